@@ -8,6 +8,10 @@ import (
 
 func main() {
 	id := strformat.NumeralCreateEnglish()
-	tbl := id.Convert(98237221597389.37182, 3)
+	tbl, err := id.ConvertDecimal("98237221597389.37182")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Println(strformat.Capitalize(tbl))
 }
@@ -0,0 +1,247 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Get reads the member of obj at path. path is a dotted list of field names or map keys, with [i] for slice and array
+// indexes and [key] for map keys, example: Server.TLS.Port, Items[2].Price or Labels["app.kubernetes.io/name"].
+// Field names follow map tags like Map. Pointers and interfaces along the path are dereferenced.
+func Get(obj interface{}, path string) (interface{}, error) {
+	s := newAccessor("reflection.Get()")
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, s.fail(reflect.TypeOf(obj), nil, err)
+	}
+	val := reflect.ValueOf(obj)
+	for _, seg := range segs {
+		val, err = s.get(val, seg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !val.IsValid() {
+		return nil, nil
+	}
+	return val.Interface(), nil
+}
+
+// Set writes value into the member at path of the value pointed by ptr, using the same path syntax as Get.
+// Nil pointers and maps along the path are allocated and slices are grown to fit the index. value is converted into
+// the type of the member like FromMap, example: Set(&cfg, "Server.Port", "8080") sets an int field.
+func Set(ptr interface{}, path string, value interface{}) error {
+	s := newAccessor("reflection.Set()")
+	val, _, ok := GetType(ptr)
+	if !ok {
+		return s.fail(reflect.TypeOf(ptr), reflect.TypeOf(value), errors.New("ptr must be a pointer"))
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return s.fail(reflect.TypeOf(ptr), reflect.TypeOf(value), err)
+	}
+	return s.set(val, segs, reflect.ValueOf(value))
+}
+
+func newAccessor(op string) *structMapper {
+	s := newStructMapper(op)
+	s.options.Lenient = false
+	return s
+}
+
+// pathPart is a member of a path parsed by parsePath
+type pathPart struct {
+	// name is the field name, map key or index
+	name string
+	// bracket is true if the part is written as [name]
+	bracket bool
+}
+
+// parsePath splits a path like Items[2].Labels["a.b"].Name into its parts
+func parsePath(path string) ([]pathPart, error) {
+	parts := []pathPart{}
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '[':
+			name, size, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, errors.New("invalid path \"" + path + "\": " + err.Error())
+			}
+			parts = append(parts, pathPart{name: name, bracket: true})
+			i += size
+		case '.':
+			if i == 0 || i+1 == len(path) || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, errors.New("invalid path \"" + path + "\": empty member at " + strconv.Itoa(i))
+			}
+			i++
+		default:
+			if i > 0 && path[i-1] == ']' {
+				return nil, errors.New("invalid path \"" + path + "\": missing . at " + strconv.Itoa(i))
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			parts = append(parts, pathPart{name: path[i : i+end]})
+			i += end
+		}
+	}
+	return parts, nil
+}
+
+// parseBracket parses [name] or ["quoted name"] at the start of str and returns the name and the parsed length
+func parseBracket(str string) (string, int, error) {
+	if len(str) > 1 && str[1] == '"' {
+		quoted, err := strconv.QuotedPrefix(str[1:])
+		if err != nil || len(str) <= len(quoted)+1 || str[len(quoted)+1] != ']' {
+			return "", 0, errors.New("unterminated quoted key")
+		}
+		name, _ := strconv.Unquote(quoted)
+		return name, len(quoted) + 2, nil
+	}
+	end := strings.IndexByte(str, ']')
+	if end < 0 {
+		return "", 0, errors.New("missing ]")
+	}
+	return str[1:end], end + 1, nil
+}
+
+// get steps from val into its member part
+func (s *structMapper) get(val reflect.Value, part pathPart) (reflect.Value, error) {
+	cur := indirect(val)
+	if !cur.IsValid() {
+		var typ reflect.Type
+		if val.IsValid() {
+			typ = val.Type()
+		}
+		return cur, s.fail(typ, nil, errors.New("value is nil"))
+	}
+	typ := cur.Type()
+	switch {
+	case cur.Kind() == reflect.Struct && !part.bracket:
+		s.pushField(part.name)
+		fld, found := lookupField(typ, part.name)
+		if !found || fld.PkgPath != "" {
+			return cur, s.fail(typ, nil, errors.New(part.name+" is not found in "+typ.String()))
+		}
+		fldVal, err := cur.FieldByIndexErr(fld.Index)
+		if err != nil {
+			return cur, s.fail(typ, fld.Type, err)
+		}
+		return fldVal, nil
+	case cur.Kind() == reflect.Map:
+		key, err := s.mapKey(typ, part.name)
+		if err != nil {
+			return cur, err
+		}
+		s.pushKey(key)
+		elem := cur.MapIndex(key)
+		if !elem.IsValid() {
+			return cur, s.fail(typ, typ.Elem(), errors.New("key "+part.name+" is not found"))
+		}
+		return elem, nil
+	case (cur.Kind() == reflect.Slice || cur.Kind() == reflect.Array) && part.bracket:
+		idx, err := s.index(typ, part.name)
+		if err != nil {
+			return cur, err
+		}
+		s.pushIndex(idx)
+		if idx >= cur.Len() {
+			return cur, s.fail(typ, typ.Elem(), errors.New("index "+part.name+" is out of range"))
+		}
+		return cur.Index(idx), nil
+	}
+	return cur, s.fail(typ, nil, errors.New("cannot get "+part.name+" of "+typ.String()))
+}
+
+// set steps from the settable value cur into the members in parts and assigns value to the last one
+func (s *structMapper) set(cur reflect.Value, parts []pathPart, value reflect.Value) error {
+	if len(parts) == 0 {
+		if !value.IsValid() {
+			cur.Set(reflect.Zero(cur.Type()))
+			return nil
+		}
+		return s.fromValue(value, cur)
+	}
+	for cur.Kind() == reflect.Ptr {
+		if cur.IsNil() {
+			cur.Set(reflect.New(cur.Type().Elem()))
+		}
+		cur = cur.Elem()
+	}
+	part := parts[0]
+	typ := cur.Type()
+	switch {
+	case cur.Kind() == reflect.Interface:
+		if cur.IsNil() || cur.Elem().Kind() != reflect.Ptr {
+			return s.fail(typ, nil, errors.New("cannot set "+part.name+" inside an interface without a pointer"))
+		}
+		return s.set(cur.Elem(), parts, value)
+	case cur.Kind() == reflect.Struct && !part.bracket:
+		s.pushField(part.name)
+		fld, found := lookupField(typ, part.name)
+		if !found || fld.PkgPath != "" {
+			return s.fail(typ, nil, errors.New(part.name+" is not found in "+typ.String()))
+		}
+		fldVal, err := cur.FieldByIndexErr(fld.Index)
+		if err != nil {
+			return s.fail(typ, fld.Type, err)
+		}
+		return s.set(fldVal, parts[1:], value)
+	case cur.Kind() == reflect.Map:
+		if cur.IsNil() {
+			cur.Set(reflect.MakeMap(typ))
+		}
+		key, err := s.mapKey(typ, part.name)
+		if err != nil {
+			return err
+		}
+		s.pushKey(key)
+		elem := reflect.New(typ.Elem()).Elem()
+		if existing := cur.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		err = s.set(elem, parts[1:], value)
+		if err != nil {
+			return err
+		}
+		cur.SetMapIndex(key, elem)
+		return nil
+	case (cur.Kind() == reflect.Slice || cur.Kind() == reflect.Array) && part.bracket:
+		idx, err := s.index(typ, part.name)
+		if err != nil {
+			return err
+		}
+		s.pushIndex(idx)
+		if idx >= cur.Len() {
+			if cur.Kind() == reflect.Array {
+				return s.fail(typ, typ.Elem(), errors.New("index "+part.name+" is out of range"))
+			}
+			grown := reflect.MakeSlice(typ, idx+1, idx+1)
+			reflect.Copy(grown, cur)
+			cur.Set(grown)
+		}
+		return s.set(cur.Index(idx), parts[1:], value)
+	}
+	return s.fail(typ, nil, errors.New("cannot set "+part.name+" of "+typ.String()))
+}
+
+// mapKey converts name into a key of map type typ
+func (s *structMapper) mapKey(typ reflect.Type, name string) (reflect.Value, error) {
+	key := reflect.New(typ.Key()).Elem()
+	err := parseDefault(name, key, s.options.TimeLayout)
+	if err != nil {
+		return key, s.fail(typ, typ.Key(), err)
+	}
+	return key, nil
+}
+
+func (s *structMapper) index(typ reflect.Type, name string) (int, error) {
+	idx, err := strconv.Atoi(strings.TrimSpace(name))
+	if err != nil || idx < 0 {
+		return 0, s.fail(typ, typ.Elem(), errors.New("invalid index "+name))
+	}
+	return idx, nil
+}
@@ -0,0 +1,310 @@
+package reflection
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// EnvTagName is the struct tag key for the environment variable bound to a field, example: `env:"DB_HOST,required"`
+	EnvTagName = "env"
+	// FlagTagName is the struct tag key for the command-line flag bound to a field, example: `flag:"db-host"`
+	FlagTagName = "flag"
+	// UsageTagName is the struct tag key for the description of a field in the usage text
+	UsageTagName = "usage"
+)
+
+// Binder fills structs from environment variables and command-line flags. A flag overrides an environment variable,
+// which overrides the default tag. On a nested struct field the env and flag tags are prefixes for the fields inside,
+// example: `env:"DB" flag:"db"` binds the Host field tagged `env:"HOST" flag:"host"` to DB_HOST and --db-host.
+type Binder struct {
+	// EnvPrefix is prepended to every environment variable, example: APP_
+	EnvPrefix string
+	// Args are the command-line arguments without the program name. Defaults to os.Args[1:] if nil.
+	// Args are only parsed if a field has a flag tag
+	Args []string
+	// LookupEnv reads an environment variable. Defaults to os.LookupEnv if nil
+	LookupEnv func(key string) (string, bool)
+	// Rest contains the arguments which are not bound flags after Bind is called, including unknown flags
+	Rest []string
+	// Strict returns an error for a flag which is not bound instead of adding it to Rest
+	Strict bool
+}
+
+// NewBinder creates a Binder reading os.Args and the environment
+func NewBinder() *Binder {
+	return &Binder{}
+}
+
+// Bind fills the struct pointed by ptr from the environment variables and command-line flags using a new Binder
+func Bind(ptr interface{}) error {
+	return NewBinder().Bind(ptr)
+}
+
+// binding is a field bound to an environment variable or a flag
+type binding struct {
+	path     string
+	env      string
+	flag     string
+	usage    string
+	def      string
+	hasDef   bool
+	required bool
+	typ      reflect.Type
+	// index contains the field index at each level of nested structs
+	index []int
+}
+
+// current gets the bound field in root, it is invalid if a nil pointer is found along the way
+func (bd binding) current(root reflect.Value) reflect.Value {
+	levels := make([][]int, len(bd.index))
+	for i, idx := range bd.index {
+		levels[i] = []int{idx}
+	}
+	return fieldByIndexPath(root, levels)
+}
+
+// Bind fills the struct pointed by ptr. Values are converted into the field types like the default tag, including slices
+// given as comma separated lists and durations. A flag given more than once is joined into a list. Every required field
+// which has no value and is still zero is returned as MappingErrors. A nil pointer to a nested struct is allocated only
+// when a flag or environment variable of a field inside it is set, otherwise it stays nil and its defaults and required
+// fields are skipped.
+func (b *Binder) Bind(ptr interface{}) error {
+	op := "reflection.Bind()"
+	val, typ, ok := GetType(ptr)
+	if !ok || typ.Kind() != reflect.Struct {
+		return newMappingError(op, reflect.TypeOf(ptr), nil, "ptr must be a pointer to a struct")
+	}
+	bindings := b.collect(typ)
+	flags, err := b.parseArgs(op, bindings)
+	if err != nil {
+		return err
+	}
+	lookupEnv := b.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	var errs MappingErrors
+	set := func(bd binding, str string) {
+		err := parseDefault(str, fieldForSet(val, bd.index), DefaultMapper.Options.TimeLayout)
+		if err != nil {
+			errs = append(errs, &MappingError{Op: op, Path: bd.path, To: bd.typ, Err: errors.New(bd.source() + ": " + err.Error())})
+		}
+	}
+	var unset []binding
+	for _, bd := range bindings {
+		str, found := "", false
+		if vals, ok := flags[bd.flag]; ok && bd.flag != "" {
+			str, found = vals[len(vals)-1], true
+			if indirectType(bd.typ).Kind() == reflect.Slice {
+				str = strings.Join(vals, ",")
+			}
+		}
+		if !found && bd.env != "" {
+			str, found = lookupEnv(bd.env)
+		}
+		if found {
+			set(bd, str)
+		} else {
+			unset = append(unset, bd)
+		}
+	}
+	// defaults and required fields are only checked inside nested structs which exist, a nil pointer is left nil
+	for _, bd := range unset {
+		cur := bd.current(val)
+		if !cur.IsValid() || !cur.IsZero() {
+			continue
+		}
+		if bd.hasDef {
+			set(bd, bd.def)
+		} else if bd.required {
+			errs = append(errs, &MappingError{Op: op, Path: bd.path, To: bd.typ, Err: errors.New("required value is not set, use " + bd.source())})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// source describes where the value of bd is read from
+func (bd binding) source() string {
+	var srcs []string
+	if bd.flag != "" {
+		srcs = append(srcs, "--"+bd.flag)
+	}
+	if bd.env != "" {
+		srcs = append(srcs, bd.env)
+	}
+	return strings.Join(srcs, " or ")
+}
+
+// Usage lists every flag and environment variable bound to the fields of the struct pointed by ptr, with their type,
+// usage tag, default tag and whether they are required
+func (b *Binder) Usage(ptr interface{}) string {
+	typ := indirectType(reflect.TypeOf(ptr))
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return ""
+	}
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	for _, bd := range b.collect(typ) {
+		flag := ""
+		if bd.flag != "" {
+			flag = "--" + bd.flag
+		}
+		desc := bd.usage
+		var notes []string
+		if bd.hasDef {
+			notes = append(notes, "default: "+bd.def)
+		}
+		if bd.required {
+			notes = append(notes, "required")
+		}
+		if len(notes) > 0 {
+			desc = strings.TrimSpace(desc + " (" + strings.Join(notes, ", ") + ")")
+		}
+		tw.Write([]byte("  " + flag + "\t" + bd.env + "\t" + bd.typ.String() + "\t" + desc + "\n"))
+	}
+	tw.Flush()
+	return sb.String()
+}
+
+// collect walks the struct type typ and returns its bound fields. A nested struct whose type is already being walked
+// is skipped, so a field like Parent *Config does not recurse.
+func (b *Binder) collect(typ reflect.Type) []binding {
+	w := bindWalker{walking: map[reflect.Type]bool{}}
+	w.walk(typ, nil, b.EnvPrefix, "")
+	return w.bindings
+}
+
+type bindWalker struct {
+	mapContext
+	bindings []binding
+	walking  map[reflect.Type]bool
+}
+
+func (w *bindWalker) walk(typ reflect.Type, index []int, envPrefix string, flagPrefix string) {
+	w.walking[typ] = true
+	defer delete(w.walking, typ)
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		env, envRequired, hasEnv := parseBindTag(fld, EnvTagName)
+		flag, flagRequired, hasFlag := parseBindTag(fld, FlagTagName)
+		if fld.PkgPath != "" || env == "-" || flag == "-" {
+			continue
+		}
+		fldIndex := append(append([]int{}, index...), i)
+		w.pushField(fld.Name)
+		if elemTyp := indirectType(fld.Type); isNestedConfig(elemTyp) {
+			if !w.walking[elemTyp] {
+				nestedEnv, nestedFlag := envPrefix, flagPrefix
+				if env != "" {
+					nestedEnv += env + "_"
+				}
+				if flag != "" {
+					nestedFlag += flag + "-"
+				}
+				w.walk(elemTyp, fldIndex, nestedEnv, nestedFlag)
+			}
+		} else if hasEnv || hasFlag {
+			bd := binding{path: w.pathString(), required: envRequired || flagRequired, typ: fld.Type, index: fldIndex, usage: fld.Tag.Get(UsageTagName)}
+			bd.def, bd.hasDef = fld.Tag.Lookup(DefaultTagName)
+			if env != "" {
+				bd.env = envPrefix + env
+			}
+			if flag != "" {
+				bd.flag = flagPrefix + flag
+			}
+			w.bindings = append(w.bindings, bd)
+		}
+		w.pop()
+	}
+}
+
+// parseBindTag parses a tag like "DB_HOST,required"
+func parseBindTag(fld reflect.StructField, key string) (name string, required bool, ok bool) {
+	val, ok := fld.Tag.Lookup(key)
+	if !ok {
+		return "", false, false
+	}
+	spl := strings.Split(val, ",")
+	for _, opt := range spl[1:] {
+		if strings.TrimSpace(opt) == "required" {
+			required = true
+		}
+	}
+	return strings.TrimSpace(spl[0]), required, true
+}
+
+// isNestedConfig checks whether typ is a struct whose fields are bound, instead of a value like time.Time
+func isNestedConfig(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !isOpaqueStruct(typ)
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// parseArgs reads the flags of Args, every value of a flag is returned in order. Args are not read if no field has a flag tag.
+func (b *Binder) parseArgs(op string, bindings []binding) (map[string][]string, error) {
+	byFlag := map[string]binding{}
+	for _, bd := range bindings {
+		if bd.flag != "" {
+			byFlag[bd.flag] = bd
+		}
+	}
+	flags := map[string][]string{}
+	b.Rest = nil
+	if len(byFlag) == 0 {
+		return flags, nil
+	}
+	args := b.Args
+	if args == nil && len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			b.Rest = append(b.Rest, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			b.Rest = append(b.Rest, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+		bd, known := byFlag[name]
+		if !known {
+			if b.Strict {
+				return nil, newMappingError(op, nil, nil, "unknown flag "+arg)
+			}
+			b.Rest = append(b.Rest, arg)
+			continue
+		}
+		if !hasValue {
+			switch {
+			case indirectType(bd.typ).Kind() == reflect.Bool:
+				value = "true"
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				return nil, &MappingError{Op: op, Path: bd.path, To: bd.typ, Err: errors.New("flag --" + name + " needs a value")}
+			}
+		}
+		flags[name] = append(flags[name], value)
+	}
+	return flags, nil
+}
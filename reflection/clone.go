@@ -0,0 +1,165 @@
+package reflection
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Clone creates a deep copy of v. Pointers, slices, maps and interfaces are copied recursively, so the result shares no
+// references with v. References shared inside v, including cycles, are shared the same way inside the result.
+// Unexported fields are copied as well. A type can implement its own copy with a Clone method returning the same type,
+// example: func (o *Order) Clone() *Order. The method is used for nested values only, not for v itself, so it can call
+// Clone and adjust the copy. Channels and functions are not copied.
+func Clone(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	cl := cloner{visited: map[cloneKey]reflect.Value{}, root: true}
+	return cl.clone(reflect.ValueOf(v)).Interface()
+}
+
+// Clone creates a deep copy of obj, which must be of the current GoType or a pointer to it. Unlike Create, nested pointers,
+// slices and maps are not shared with obj. Pointer of a pointer is supported.
+func (typ *GoType) Clone(obj interface{}) (interface{}, error) {
+	if obj == nil {
+		return typ.Create(nil)
+	}
+	objTyp := reflect.TypeOf(obj)
+	switch {
+	case objTyp == typ.Type:
+		return Clone(obj), nil
+	case objTyp.Kind() == reflect.Ptr && objTyp.Elem() == typ.Type:
+		val := reflect.ValueOf(obj)
+		if val.IsNil() {
+			return reflect.Zero(typ.Type).Interface(), nil
+		}
+		return Clone(val.Elem().Interface()), nil
+	case typ.IsPtr() && typ.Type.Elem() == objTyp:
+		res := reflect.New(objTyp)
+		res.Elem().Set(reflect.ValueOf(Clone(obj)))
+		return res.Interface(), nil
+	}
+	return nil, newMappingError("GoType.Clone()", objTyp, typ.Type, "Cannot clone a "+objTyp.String()+" into a "+typ.Type.String())
+}
+
+type cloner struct {
+	// visited maps the references already copied to their copy
+	visited map[cloneKey]reflect.Value
+	// root is true until the value passed to Clone is copied, its Clone method is not called
+	root bool
+}
+
+type cloneKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// clone returns a deep copy of src, src must not be obtained from an unexported field
+func (cl *cloner) clone(src reflect.Value) reflect.Value {
+	typ := src.Type()
+	switch src.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if src.IsNil() {
+			return reflect.Zero(typ)
+		}
+	}
+	if cl.root {
+		cl.root = false
+	} else if res, ok := cloneMethod(src); ok {
+		return res
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		key := cloneKey{src.Pointer(), typ, 0}
+		if res, ok := cl.visited[key]; ok {
+			return res
+		}
+		res := reflect.New(typ.Elem())
+		cl.visited[key] = res
+		res.Elem().Set(cl.clone(src.Elem()))
+		return res
+	case reflect.Interface:
+		res := reflect.New(typ).Elem()
+		res.Set(cl.clone(src.Elem()))
+		return res
+	case reflect.Struct:
+		return cl.cloneStruct(src)
+	case reflect.Slice:
+		key := cloneKey{src.Pointer(), typ, src.Len()}
+		if res, ok := cl.visited[key]; ok {
+			return res
+		}
+		res := reflect.MakeSlice(typ, src.Len(), src.Cap())
+		cl.visited[key] = res
+		for i := 0; i < src.Len(); i++ {
+			res.Index(i).Set(cl.clone(src.Index(i)))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(typ).Elem()
+		for i := 0; i < src.Len(); i++ {
+			res.Index(i).Set(cl.clone(src.Index(i)))
+		}
+		return res
+	case reflect.Map:
+		key := cloneKey{src.Pointer(), typ, 0}
+		if res, ok := cl.visited[key]; ok {
+			return res
+		}
+		res := reflect.MakeMapWithSize(typ, src.Len())
+		cl.visited[key] = res
+		iter := src.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(cl.clone(iter.Key()), cl.clone(iter.Value()))
+		}
+		return res
+	}
+	res := reflect.New(typ).Elem()
+	res.Set(src)
+	return res
+}
+
+func (cl *cloner) cloneStruct(src reflect.Value) reflect.Value {
+	typ := src.Type()
+	res := reflect.New(typ).Elem()
+	if typ == timeType {
+		// the location of a time.Time must stay shared, example: time.UTC
+		res.Set(src)
+		return res
+	}
+	if !src.CanAddr() {
+		tmp := reflect.New(typ).Elem()
+		tmp.Set(src)
+		src = tmp
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		accessible(res.Field(i)).Set(cl.clone(accessible(src.Field(i))))
+	}
+	return res
+}
+
+// accessible makes an unexported field of an addressable struct readable and settable
+func accessible(fld reflect.Value) reflect.Value {
+	if fld.CanSet() {
+		return fld
+	}
+	return reflect.NewAt(fld.Type(), unsafe.Pointer(fld.UnsafeAddr())).Elem()
+}
+
+// cloneMethod calls the Clone method of src if it returns the type of src
+func cloneMethod(src reflect.Value) (reflect.Value, bool) {
+	if !src.CanInterface() {
+		return reflect.Value{}, false
+	}
+	method := src.MethodByName("Clone")
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+	mt := method.Type()
+	if mt.NumIn() != 0 || mt.NumOut() != 1 || mt.Out(0) != src.Type() {
+		return reflect.Value{}, false
+	}
+	return method.Call(nil)[0], true
+}
@@ -0,0 +1,239 @@
+package reflection
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// convertValue converts frVal into toVal when their types differ. Returns false if there is no conversion between both types.
+func convertValue(frVal reflect.Value, toVal reflect.Value, timeLayout string) (bool, error) {
+	frTyp := frVal.Type()
+	toTyp := toVal.Type()
+	if frTyp == toTyp {
+		return false, nil
+	}
+	if timeLayout == "" {
+		timeLayout = time.RFC3339
+	}
+	frKind := frVal.Kind()
+	toKind := toVal.Kind()
+
+	if frTyp == timeType && toKind == reflect.String {
+		toVal.SetString(frVal.Interface().(time.Time).Format(timeLayout))
+		return true, nil
+	}
+	if toTyp == timeType && frKind == reflect.String {
+		t, err := time.Parse(timeLayout, frVal.String())
+		if err != nil {
+			return true, errors.New("cannot convert \"" + frVal.String() + "\" to time.Time: " + err.Error())
+		}
+		toVal.Set(reflect.ValueOf(t))
+		return true, nil
+	}
+	if isNumberKind(frKind) && isNumberKind(toKind) {
+		return true, convertNumber(frVal, toVal)
+	}
+	if frKind == reflect.String && toKind != reflect.String {
+		return parseString(frVal.String(), toVal)
+	}
+	if toKind == reflect.String && frKind != reflect.String {
+		str, ok := formatValue(frVal)
+		if ok {
+			toVal.SetString(str)
+		}
+		return ok, nil
+	}
+	return false, nil
+}
+
+// hasConversion checks whether convertValue can convert a value of type from into type to
+func hasConversion(from reflect.Type, to reflect.Type) bool {
+	if from == to {
+		return false
+	}
+	frKind := from.Kind()
+	toKind := to.Kind()
+	switch {
+	case from == timeType:
+		return toKind == reflect.String
+	case to == timeType:
+		return frKind == reflect.String
+	case isNumberKind(frKind) && isNumberKind(toKind):
+		return true
+	case frKind == reflect.String && toKind != reflect.String:
+		return isNumberKind(toKind) || isScalarKind(toKind)
+	case toKind == reflect.String && frKind != reflect.String:
+		return isNumberKind(frKind) || isScalarKind(frKind)
+	}
+	return false
+}
+
+// isScalarKind checks whether kind is a bool or complex number
+func isScalarKind(kind reflect.Kind) bool {
+	return kind == reflect.Bool || kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || isUintKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind == reflect.Int || kind == reflect.Int8 || kind == reflect.Int16 || kind == reflect.Int32 || kind == reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind == reflect.Uint || kind == reflect.Uint8 || kind == reflect.Uint16 || kind == reflect.Uint32 || kind == reflect.Uint64 || kind == reflect.Uintptr
+}
+
+// convertNumber widens or narrows a number, returns an error if the value does not fit into toVal
+func convertNumber(frVal reflect.Value, toVal reflect.Value) error {
+	frKind := frVal.Kind()
+	toKind := toVal.Kind()
+	overflow := errors.New("value " + numberString(frVal) + " overflows " + toVal.Type().String())
+
+	switch {
+	case isIntKind(frKind):
+		v := frVal.Int()
+		switch {
+		case isIntKind(toKind):
+			if toVal.OverflowInt(v) {
+				return overflow
+			}
+			toVal.SetInt(v)
+		case isUintKind(toKind):
+			if v < 0 || toVal.OverflowUint(uint64(v)) {
+				return overflow
+			}
+			toVal.SetUint(uint64(v))
+		default:
+			toVal.SetFloat(float64(v))
+		}
+	case isUintKind(frKind):
+		v := frVal.Uint()
+		switch {
+		case isIntKind(toKind):
+			if v > math.MaxInt64 || toVal.OverflowInt(int64(v)) {
+				return overflow
+			}
+			toVal.SetInt(int64(v))
+		case isUintKind(toKind):
+			if toVal.OverflowUint(v) {
+				return overflow
+			}
+			toVal.SetUint(v)
+		default:
+			toVal.SetFloat(float64(v))
+		}
+	default:
+		v := frVal.Float()
+		if isIntKind(toKind) || isUintKind(toKind) {
+			if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) {
+				return errors.New("value " + numberString(frVal) + " cannot be converted to " + toVal.Type().String() + " without losing its fraction")
+			}
+		}
+		switch {
+		case isIntKind(toKind):
+			if v < math.MinInt64 || v >= math.MaxInt64 || toVal.OverflowInt(int64(v)) {
+				return overflow
+			}
+			toVal.SetInt(int64(v))
+		case isUintKind(toKind):
+			if v < 0 || v >= math.MaxUint64 || toVal.OverflowUint(uint64(v)) {
+				return overflow
+			}
+			toVal.SetUint(uint64(v))
+		default:
+			if toVal.OverflowFloat(v) {
+				return overflow
+			}
+			toVal.SetFloat(v)
+		}
+	}
+	return nil
+}
+
+// parseString parses str into toVal. Returns false if toVal is not a number, bool or complex
+func parseString(str string, toVal reflect.Value) (bool, error) {
+	str = strings.TrimSpace(str)
+	kind := toVal.Kind()
+	var parsed reflect.Value
+	switch {
+	case isIntKind(kind):
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return true, parseError(str, toVal.Type(), err)
+		}
+		parsed = reflect.ValueOf(v)
+	case isUintKind(kind):
+		v, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return true, parseError(str, toVal.Type(), err)
+		}
+		parsed = reflect.ValueOf(v)
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		v, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return true, parseError(str, toVal.Type(), err)
+		}
+		parsed = reflect.ValueOf(v)
+	case kind == reflect.Bool:
+		v, err := strconv.ParseBool(str)
+		if err != nil {
+			return true, parseError(str, toVal.Type(), err)
+		}
+		toVal.SetBool(v)
+		return true, nil
+	case kind == reflect.Complex64 || kind == reflect.Complex128:
+		v, err := strconv.ParseComplex(str, 128)
+		if err != nil {
+			return true, parseError(str, toVal.Type(), err)
+		}
+		toVal.SetComplex(v)
+		return true, nil
+	default:
+		return false, nil
+	}
+	return true, convertNumber(parsed, toVal)
+}
+
+func parseError(str string, typ reflect.Type, err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		err = numErr.Err
+	}
+	return errors.New("cannot convert \"" + str + "\" to " + typ.String() + ": " + err.Error())
+}
+
+// formatValue formats a number, bool or complex into a string
+func formatValue(val reflect.Value) (string, bool) {
+	kind := val.Kind()
+	switch {
+	case isNumberKind(kind):
+		return numberString(val), true
+	case kind == reflect.Bool:
+		return strconv.FormatBool(val.Bool()), true
+	case kind == reflect.Complex64:
+		return strconv.FormatComplex(val.Complex(), 'f', -1, 64), true
+	case kind == reflect.Complex128:
+		return strconv.FormatComplex(val.Complex(), 'f', -1, 128), true
+	}
+	return "", false
+}
+
+func numberString(val reflect.Value) string {
+	kind := val.Kind()
+	switch {
+	case isIntKind(kind):
+		return strconv.FormatInt(val.Int(), 10)
+	case isUintKind(kind):
+		return strconv.FormatUint(val.Uint(), 10)
+	case kind == reflect.Float32:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 32)
+	}
+	return strconv.FormatFloat(val.Float(), 'f', -1, 64)
+}
@@ -0,0 +1,304 @@
+package reflection
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CSVTagName is the struct tag key for the header of a CSV column, `csv:"-"` omits a field
+const CSVTagName = "csv"
+
+// CSVError is a value that cannot be read from or written to a CSV row
+type CSVError struct {
+	// Line is the line of the row in the CSV, starting from 1
+	Line int
+	// Column is the header of the column, example: Customer.Email
+	Column string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *CSVError) Error() string {
+	msg := "line " + strconv.Itoa(e.Line)
+	if e.Column != "" {
+		msg += ", column " + e.Column
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// csvColumn is a field of a struct written as a CSV column
+type csvColumn struct {
+	header string
+	// index contains the field index at each level of nested structs
+	index []int
+}
+
+// csvColumns lists the columns of struct typ, nested structs are flattened with dotted headers.
+// path contains the struct types being flattened, a field of one of them cannot be flattened and returns an error.
+func csvColumns(typ reflect.Type, prefix string, index []int, cols []csvColumn, path map[reflect.Type]bool) ([]csvColumn, error) {
+	path[typ] = true
+	defer delete(path, typ)
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		name := fld.Tag.Get(CSVTagName)
+		if fld.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = fld.Name
+		}
+		fldIndex := append(append([]int{}, index...), i)
+		if elemTyp := indirectType(fld.Type); elemTyp.Kind() == reflect.Struct && !isOpaqueStruct(elemTyp) {
+			if path[elemTyp] {
+				return nil, errors.New("column " + prefix + name + " has the recursive type " + elemTyp.String() + ", skip it with `csv:\"-\"`")
+			}
+			var err error
+			cols, err = csvColumns(elemTyp, prefix+name+".", fldIndex, cols, path)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cols = append(cols, csvColumn{header: prefix + name, index: fldIndex})
+	}
+	return cols, nil
+}
+
+// structType gets the struct type of a struct or pointer to struct row
+func structType(row interface{}) (reflect.Type, bool) {
+	typ := indirectType(reflect.TypeOf(row))
+	return typ, typ != nil && typ.Kind() == reflect.Struct
+}
+
+// CSVWriter writes structs as CSV rows, the header is written before the first row
+type CSVWriter struct {
+	// TimeLayout is the layout of time.Time columns. Defaults to time.RFC3339
+	TimeLayout string
+
+	w       *csv.Writer
+	typ     reflect.Type
+	columns []csvColumn
+	line    int
+}
+
+// NewCSVWriter creates a CSVWriter writing into w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes row, a struct or a pointer to it. Every row must be of the same type.
+func (cw *CSVWriter) Write(row interface{}) error {
+	typ, ok := structType(row)
+	if !ok {
+		return &CSVError{Line: cw.line + 1, Err: errors.New("row must be a struct or a pointer to a struct")}
+	}
+	if cw.typ == nil {
+		columns, err := csvColumns(typ, "", nil, nil, map[reflect.Type]bool{})
+		if err != nil {
+			return &CSVError{Line: cw.line + 1, Err: err}
+		}
+		cw.typ = typ
+		cw.columns = columns
+		header := make([]string, len(cw.columns))
+		for i, col := range cw.columns {
+			header[i] = col.header
+		}
+		if err := cw.w.Write(header); err != nil {
+			return err
+		}
+		cw.line++
+	}
+	if typ != cw.typ {
+		return &CSVError{Line: cw.line + 1, Err: errors.New("row must be a " + cw.typ.String() + " instead of " + typ.String())}
+	}
+	cw.line++
+	val := indirect(reflect.ValueOf(row))
+	record := make([]string, len(cw.columns))
+	for i, col := range cw.columns {
+		fldVal := fieldByIndexPath(val, [][]int{col.index})
+		str, err := formatCell(fldVal, cw.TimeLayout)
+		if err != nil {
+			return &CSVError{Line: cw.line, Column: col.header, Err: err}
+		}
+		record[i] = str
+	}
+	return cw.w.Write(record)
+}
+
+// Flush writes the buffered rows into the underlying io.Writer
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatCell converts a field into a CSV value, nil is written as an empty value. Slices are joined with commas, an element
+// containing a comma returns an error because it cannot be read back as the same element.
+func formatCell(val reflect.Value, timeLayout string) (string, error) {
+	val = indirect(val)
+	if !val.IsValid() {
+		return "", nil
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, val.Len())
+		for i := range items {
+			item, err := formatCell(val.Index(i), timeLayout)
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(item, ",") {
+				return "", errors.New("element " + strconv.Itoa(i) + " contains the separator ',', it would be read back as several elements")
+			}
+			items[i] = item
+		}
+		return strings.Join(items, ","), nil
+	}
+	str := reflect.New(reflect.TypeOf("")).Elem()
+	ok, err := convertValue(val, str, timeLayout)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.New("cannot convert " + val.Type().String() + " to a CSV value")
+	}
+	return str.String(), nil
+}
+
+// WriteCSV writes every element of the slice rows into w, including the header
+func WriteCSV(w io.Writer, rows interface{}) error {
+	val := indirect(reflect.ValueOf(rows))
+	if !val.IsValid() || (val.Kind() != reflect.Slice && val.Kind() != reflect.Array) {
+		return errors.New("rows must be a slice")
+	}
+	cw := NewCSVWriter(w)
+	for i := 0; i < val.Len(); i++ {
+		err := cw.Write(val.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// CSVReader reads CSV rows into structs. The first row is the header, columns are matched to fields by their header
+// and columns without a matching field are ignored.
+type CSVReader struct {
+	// TimeLayout is the layout of time.Time columns. Defaults to time.RFC3339
+	TimeLayout string
+
+	r       *csv.Reader
+	header  []string
+	typ     reflect.Type
+	columns []*csvColumn
+}
+
+// NewCSVReader creates a CSVReader reading from r
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := &CSVReader{r: csv.NewReader(r)}
+	cr.r.FieldsPerRecord = -1
+	return cr
+}
+
+// Read reads the next row into the struct pointed by ptr and returns io.EOF when there are no more rows.
+// Values are converted like MapOptions.ConvertTypes, empty values are left as zero. A value which cannot be
+// converted is returned as a *CSVError with its line and column.
+func (cr *CSVReader) Read(ptr interface{}) error {
+	val, typ, ok := GetType(ptr)
+	if !ok || typ.Kind() != reflect.Struct {
+		return errors.New("ptr must be a pointer to a struct")
+	}
+	if cr.header == nil {
+		header, err := cr.r.Read()
+		if err != nil {
+			return err
+		}
+		cr.header = header
+	}
+	if typ != cr.typ {
+		cols, err := csvColumns(typ, "", nil, nil, map[reflect.Type]bool{})
+		if err != nil {
+			return &CSVError{Line: 1, Err: err}
+		}
+		cr.typ = typ
+		cr.columns = make([]*csvColumn, len(cr.header))
+		for i, name := range cr.header {
+			for j := range cols {
+				if cols[j].header == strings.TrimSpace(name) {
+					cr.columns[i] = &cols[j]
+				}
+			}
+		}
+	}
+	record, err := cr.r.Read()
+	if err != nil {
+		return err
+	}
+	for i, str := range record {
+		if i >= len(cr.columns) || cr.columns[i] == nil || str == "" {
+			continue
+		}
+		col := cr.columns[i]
+		err := parseDefault(str, fieldForSet(val, col.index), cr.TimeLayout)
+		if err != nil {
+			line, _ := cr.r.FieldPos(i)
+			return &CSVError{Line: line, Column: col.header, Err: err}
+		}
+	}
+	return nil
+}
+
+// fieldForSet gets the field of struct val at index, nil pointers along the path are allocated
+func fieldForSet(val reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(i)
+	}
+	return val
+}
+
+// ReadCSV reads every row of r into the slice pointed by ptrToSlice, whose elements are structs or pointers to structs
+func ReadCSV(r io.Reader, ptrToSlice interface{}) error {
+	val, typ, ok := GetType(ptrToSlice)
+	if !ok || typ.Kind() != reflect.Slice {
+		return errors.New("ptrToSlice must be a pointer to a slice")
+	}
+	elemTyp := typ.Elem()
+	isPtr := elemTyp.Kind() == reflect.Ptr
+	if isPtr {
+		elemTyp = elemTyp.Elem()
+	}
+	if elemTyp.Kind() != reflect.Struct {
+		return errors.New("ptrToSlice must be a pointer to a slice of structs")
+	}
+	cr := NewCSVReader(r)
+	for {
+		row := reflect.New(elemTyp)
+		err := cr.Read(row.Interface())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !isPtr {
+			row = row.Elem()
+		}
+		val.Set(reflect.Append(val, row))
+	}
+}
@@ -0,0 +1,38 @@
+package reflection
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type csvTagged struct {
+	Name string
+	Tags []string
+}
+
+func TestCSVSliceRoundTrip(t *testing.T) {
+	rows := []csvTagged{{"a", []string{"x", "y"}}, {"b", []string{"z"}}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	var got []csvTagged
+	if err := ReadCSV(&buf, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := rows
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadCSV() = %v, want %v", got, want)
+	}
+}
+
+func TestCSVSliceElementWithSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []csvTagged{{"a", []string{"a,b"}}})
+	var csvErr *CSVError
+	if !errors.As(err, &csvErr) || csvErr.Line != 2 || csvErr.Column != "Tags" {
+		t.Errorf("WriteCSV() error = %v, want a CSVError at line 2, column Tags", err)
+	}
+}
@@ -0,0 +1,183 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultTagName is the struct tag key read by ApplyDefaults, example: `default:"8080"`
+const DefaultTagName = "default"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyDefaults sets every field of the struct pointed by ptr which is still a zero value to the value of its default tag.
+// Numbers, bools, strings, time.Duration (example: 1m30s), time.Time (using DefaultMapper.Options.TimeLayout) and slices
+// given as a comma separated list are supported. Nested structs are filled recursively, nil pointers to structs
+// containing default tags are allocated.
+func ApplyDefaults(ptr interface{}) error {
+	op := "reflection.ApplyDefaults()"
+	val, typ, ok := GetType(ptr)
+	if !ok {
+		return newMappingError(op, reflect.TypeOf(ptr), nil, "ptr must be a pointer")
+	}
+	if typ.Kind() != reflect.Struct {
+		return newMappingError(op, typ, nil, "ptr must be a pointer to a struct")
+	}
+	d := defaulter{
+		mapContext: mapContext{op: op, options: MapOptions{TimeLayout: DefaultMapper.Options.TimeLayout}},
+		visiting:   map[visitKey]bool{},
+		filling:    map[reflect.Type]bool{},
+	}
+	return d.fill(val)
+}
+
+// defaulter holds the state of a single ApplyDefaults call
+type defaulter struct {
+	mapContext
+	// visiting contains the pointers currently being filled, to stop at cycles
+	visiting map[visitKey]bool
+	// filling contains the struct types currently being filled, nil pointers to them are not allocated
+	filling map[reflect.Type]bool
+}
+
+func (d *defaulter) fill(val reflect.Value) error {
+	typ := val.Type()
+	d.filling[typ] = true
+	defer delete(d.filling, typ)
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		if fld.PkgPath != "" {
+			continue
+		}
+		d.pushField(fld.Name)
+		err := d.fillField(val.Field(i), fld)
+		d.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *defaulter) fillField(fldVal reflect.Value, fld reflect.StructField) error {
+	tag, ok := fld.Tag.Lookup(DefaultTagName)
+	if ok && fldVal.IsZero() {
+		err := parseDefault(tag, fldVal, d.options.TimeLayout)
+		if err != nil {
+			return d.fail(nil, fld.Type, err)
+		}
+	}
+	return d.walk(fldVal)
+}
+
+// walk fills the structs inside val
+func (d *defaulter) walk(val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.Ptr:
+		elemTyp := val.Type().Elem()
+		if val.IsNil() {
+			if elemTyp.Kind() != reflect.Struct || d.filling[elemTyp] || !hasDefaults(elemTyp, map[reflect.Type]bool{}) {
+				return nil
+			}
+			val.Set(reflect.New(elemTyp))
+		}
+		key := visitKey{val.Pointer(), val.Type()}
+		if d.visiting[key] {
+			return nil
+		}
+		d.visiting[key] = true
+		defer delete(d.visiting, key)
+		return d.walk(val.Elem())
+	case reflect.Struct:
+		if isOpaqueStruct(val.Type()) {
+			return nil
+		}
+		return d.fill(val)
+	case reflect.Slice, reflect.Array:
+		if isPlainType(val.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < val.Len(); i++ {
+			d.pushIndex(i)
+			err := d.walk(val.Index(i))
+			d.pop()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasDefaults checks whether struct typ or its nested structs contain a default tag
+func hasDefaults(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return false
+	}
+	seen[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		if fld.PkgPath != "" {
+			continue
+		}
+		if _, ok := fld.Tag.Lookup(DefaultTagName); ok || hasDefaults(fld.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDefault parses the default tag str into toVal
+func parseDefault(str string, toVal reflect.Value, timeLayout string) error {
+	typ := toVal.Type()
+	switch {
+	case typ == durationType:
+		dur, err := time.ParseDuration(strings.TrimSpace(str))
+		if err != nil {
+			return err
+		}
+		toVal.SetInt(int64(dur))
+		return nil
+	case toVal.Kind() == reflect.String:
+		toVal.SetString(str)
+		return nil
+	case toVal.Kind() == reflect.Ptr:
+		elem := reflect.New(typ.Elem())
+		err := parseDefault(str, elem.Elem(), timeLayout)
+		if err != nil {
+			return err
+		}
+		toVal.Set(elem)
+		return nil
+	case toVal.Kind() == reflect.Slice || toVal.Kind() == reflect.Array:
+		items := []string{}
+		if strings.TrimSpace(str) != "" {
+			items = strings.Split(str, ",")
+		}
+		if toVal.Kind() == reflect.Slice {
+			toVal.Set(reflect.MakeSlice(typ, len(items), len(items)))
+		} else if len(items) > toVal.Len() {
+			return errors.New("too many values for " + typ.String())
+		}
+		for i, item := range items {
+			err := parseDefault(strings.TrimSpace(item), toVal.Index(i), timeLayout)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ok, err := convertValue(reflect.ValueOf(strings.TrimSpace(str)), toVal, timeLayout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("default values are not supported for " + typ.String())
+	}
+	return nil
+}
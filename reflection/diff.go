@@ -0,0 +1,295 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Change is a difference between two values found by Diff
+type Change struct {
+	// Path is the path of the changed member, example: Items[2].Price. Empty if the values themselves differ.
+	Path string
+	// Old is the member of the first value, nil if the member is added
+	Old interface{}
+	// New is the member of the second value, nil if the member is removed
+	New interface{}
+}
+
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + ToString(c.Old) + " -> " + ToString(c.New)
+}
+
+// DiffOptions configures Diff and Equal
+type DiffOptions struct {
+	// KeyField matches elements of slices of structs by the value of this field instead of by index, example: ID
+	KeyField string
+	// Ignore contains the members that are not compared, either as a full path (Items[2].Price) or without indexes (Items.Price)
+	Ignore []string
+}
+
+// Diff compares a and b and returns the list of changed members. Struct members are matched by name and map tags like Map.
+// Structs, slices and arrays (by index), maps and pointers are compared recursively.
+func Diff(a interface{}, b interface{}) []Change {
+	return DiffWithOptions(a, b, DiffOptions{})
+}
+
+// DiffWithOptions is the same as Diff, but uses the specified options
+func DiffWithOptions(a interface{}, b interface{}, opts DiffOptions) []Change {
+	d := differ{opts: opts, visited: map[diffVisit]bool{}}
+	d.diff("", "", reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.changes
+}
+
+// Equal checks whether a and b have no differences according to Diff
+func Equal(a interface{}, b interface{}) bool {
+	return len(Diff(a, b)) == 0
+}
+
+// EqualWithOptions is the same as Equal, but uses the specified options. Use DiffOptions.Ignore to skip members.
+func EqualWithOptions(a interface{}, b interface{}, opts DiffOptions) bool {
+	return len(DiffWithOptions(a, b, opts)) == 0
+}
+
+type differ struct {
+	opts    DiffOptions
+	changes []Change
+	// visited contains pairs of pointers already compared, to stop at cycles
+	visited map[diffVisit]bool
+}
+
+type diffVisit struct {
+	a   uintptr
+	b   uintptr
+	typ reflect.Type
+}
+
+func (d *differ) change(path string, a reflect.Value, b reflect.Value) {
+	d.changes = append(d.changes, Change{Path: path, Old: valueInterface(a), New: valueInterface(b)})
+}
+
+func valueInterface(val reflect.Value) interface{} {
+	if !val.IsValid() || !val.CanInterface() {
+		return nil
+	}
+	return val.Interface()
+}
+
+// ignored checks whether path or path without indexes is in DiffOptions.Ignore
+func (d *differ) ignored(path string, plain string) bool {
+	for _, ign := range d.opts.Ignore {
+		if ign == path || ign == plain {
+			return true
+		}
+	}
+	return false
+}
+
+// diff compares a and b. path is the full path of the member and plain is the path without indexes.
+func (d *differ) diff(path string, plain string, a reflect.Value, b reflect.Value) {
+	if d.ignored(path, plain) {
+		return
+	}
+	for a.IsValid() && a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	for b.IsValid() && b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.change(path, a, b)
+		}
+		return
+	}
+	if a.Kind() != b.Kind() {
+		d.change(path, a, b)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.change(path, a, b)
+			}
+			return
+		}
+		visit := diffVisit{a.Pointer(), b.Pointer(), a.Type()}
+		if d.visited[visit] {
+			return
+		}
+		d.visited[visit] = true
+		d.diff(path, plain, a.Elem(), b.Elem())
+	case reflect.Struct:
+		d.diffStruct(path, plain, a, b)
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			d.change(path, a, b)
+			return
+		}
+		if d.opts.KeyField != "" && isStructType(a.Type().Elem()) && isStructType(b.Type().Elem()) {
+			d.diffKeyed(path, plain, a, b)
+			return
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			elemPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= b.Len():
+				d.change(elemPath, a.Index(i), reflect.Value{})
+			case i >= a.Len():
+				d.change(elemPath, reflect.Value{}, b.Index(i))
+			default:
+				d.diff(elemPath, plain, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		d.diffMap(path, plain, a, b)
+	default:
+		if !scalarEqual(a, b) {
+			d.change(path, a, b)
+		}
+	}
+}
+
+func (d *differ) diffStruct(path string, plain string, a reflect.Value, b reflect.Value) {
+	typ := a.Type()
+	if typ == b.Type() && isOpaqueStruct(typ) {
+		if !opaqueEqual(a, b) {
+			d.change(path, a, b)
+		}
+		return
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		tag := parseMapTag(fld)
+		if fld.PkgPath != "" || tag.Ignore {
+			continue
+		}
+		// the same type is compared field by field, the map tag only resolves fields between different types
+		var bFld reflect.Value
+		if typ == b.Type() {
+			bFld = b.Field(i)
+		} else {
+			var exists bool
+			bFld, exists = sourceField(b, tag.Name)
+			if !exists {
+				continue
+			}
+		}
+		d.diff(fieldPath(path, fld.Name), fieldPath(plain, fld.Name), a.Field(i), bFld)
+	}
+}
+
+// diffKeyed compares elements of two slices of structs which have the same DiffOptions.KeyField
+func (d *differ) diffKeyed(path string, plain string, a reflect.Value, b reflect.Value) {
+	bElems := map[interface{}]reflect.Value{}
+	bKeys := []interface{}{}
+	for i := 0; i < b.Len(); i++ {
+		key := d.elemKey(b.Index(i))
+		if key != nil {
+			bElems[key] = b.Index(i)
+			bKeys = append(bKeys, key)
+		}
+	}
+	seen := map[interface{}]bool{}
+	for i := 0; i < a.Len(); i++ {
+		key := d.elemKey(a.Index(i))
+		elemPath := path + "[" + d.opts.KeyField + "=" + fmt.Sprint(key) + "]"
+		bElem, found := bElems[key]
+		if key == nil || !found {
+			d.change(elemPath, a.Index(i), reflect.Value{})
+			continue
+		}
+		seen[key] = true
+		d.diff(elemPath, plain, a.Index(i), bElem)
+	}
+	for _, key := range bKeys {
+		if !seen[key] {
+			d.change(path+"["+d.opts.KeyField+"="+fmt.Sprint(key)+"]", reflect.Value{}, bElems[key])
+		}
+	}
+}
+
+// elemKey gets the value of DiffOptions.KeyField of a struct element, nil if it does not exist
+func (d *differ) elemKey(elem reflect.Value) interface{} {
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return nil
+		}
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	key, _ := sourceField(elem, d.opts.KeyField)
+	if !key.IsValid() || !key.Type().Comparable() {
+		return nil
+	}
+	return key.Interface()
+}
+
+func (d *differ) diffMap(path string, plain string, a reflect.Value, b reflect.Value) {
+	// keys of another type cannot index the other map, so the maps are compared as a whole
+	if a.IsNil() != b.IsNil() || a.Type().Key() != b.Type().Key() {
+		d.change(path, a, b)
+		return
+	}
+	keys := a.MapKeys()
+	for _, key := range b.MapKeys() {
+		if !a.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+	for _, key := range keys {
+		elemPath := path + "[" + fmt.Sprint(valueInterface(key)) + "]"
+		d.diff(elemPath, plain, a.MapIndex(key), b.MapIndex(key))
+	}
+}
+
+func isStructType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// scalarEqual compares two values of the same kind which are not a struct, slice, array, map or pointer
+func scalarEqual(a reflect.Value, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	}
+	if a.CanInterface() && b.CanInterface() && a.Type() == b.Type() {
+		return a.Interface() == b.Interface()
+	}
+	return printValue(a) == printValue(b)
+}
+
+// opaqueEqual compares structs without exported fields, using their Equal method if it exists (like time.Time)
+func opaqueEqual(a reflect.Value, b reflect.Value) bool {
+	if !a.CanInterface() || !b.CanInterface() {
+		return true
+	}
+	method := a.MethodByName("Equal")
+	if method.IsValid() && method.Type().NumIn() == 1 && method.Type().NumOut() == 1 &&
+		a.Type().AssignableTo(method.Type().In(0)) && method.Type().Out(0).Kind() == reflect.Bool {
+		return method.Call([]reflect.Value{b})[0].Bool()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func fieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
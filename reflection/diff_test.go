@@ -0,0 +1,53 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffWide struct {
+	X int
+	Y string
+	Z bool
+}
+
+type diffNarrow struct {
+	X int
+}
+
+type diffRenamed struct {
+	Value int `map:"X"`
+	Y     string
+}
+
+func TestDiffDifferentStructTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		want []Change
+	}{
+		{"fewer fields equal", diffWide{X: 1, Y: "a"}, diffNarrow{X: 1}, nil},
+		{"fewer fields changed", diffWide{X: 1, Y: "a"}, diffNarrow{X: 2}, []Change{{Path: "X", Old: 1, New: 2}}},
+		{"more fields", diffNarrow{X: 1}, diffWide{X: 3, Y: "b"}, []Change{{Path: "X", Old: 1, New: 3}}},
+		{"map tag", diffRenamed{Value: 1, Y: "a"}, diffWide{X: 2, Y: "a"}, []Change{{Path: "Value", Old: 1, New: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMapsWithDifferentKeyTypes(t *testing.T) {
+	a := map[string]int{"a": 1}
+	b := map[int]int{1: 1}
+	got := Diff(a, b)
+	want := []Change{{Path: "", Old: a, New: b}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// MappingError is returned when a value cannot be mapped. Use errors.As to get it from an error.
+type MappingError struct {
+	// Op is the operation that failed, example: mapper.Map()
+	Op string
+	// Path is the path of the member that failed, example: Orders[3].Customer.Email. Empty if the root value failed.
+	Path string
+	// From is the type of the source member
+	From reflect.Type
+	// To is the type of the destination member
+	To reflect.Type
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *MappingError) Error() string {
+	msg := e.Err.Error()
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	if e.Op != "" {
+		msg = e.Op + " - " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// MappingErrors contains every failure collected when MapOptions.Lenient is set
+type MappingErrors []*MappingError
+
+func (e MappingErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns every failure, so errors.Is and errors.As check each of them
+func (e MappingErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+func newMappingError(op string, from reflect.Type, to reflect.Type, msg string) *MappingError {
+	return &MappingError{Op: op, From: from, To: to, Err: errors.New(msg)}
+}
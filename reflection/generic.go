@@ -0,0 +1,95 @@
+package reflection
+
+import (
+	"reflect"
+)
+
+// MapTo maps src, a struct or a pointer to it, into a new T using DefaultMapper. T can be a struct or a pointer to a struct.
+func MapTo[T any](src interface{}) (T, error) {
+	var res T
+	err := mapInto(DefaultMapper, src, reflect.ValueOf(&res).Elem())
+	return res, err
+}
+
+// MapSliceTo maps src, a slice or a pointer to it, into a new []T using DefaultMapper
+func MapSliceTo[T any](src interface{}) ([]T, error) {
+	var res []T
+	err := DefaultMapper.MapSlice(pointerTo(src), &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// mapInto maps src into toVal, allocating toVal if it is a nil pointer
+func mapInto(m *Mapper, src interface{}, toVal reflect.Value) error {
+	for toVal.Kind() == reflect.Ptr {
+		if toVal.IsNil() {
+			toVal.Set(reflect.New(toVal.Type().Elem()))
+		}
+		toVal = toVal.Elem()
+	}
+	return m.Map(pointerTo(src), toVal.Addr().Interface())
+}
+
+// pointerTo returns obj if it is a pointer, otherwise a pointer to a copy of obj
+func pointerTo(obj interface{}) interface{} {
+	val := reflect.ValueOf(obj)
+	if !val.IsValid() || val.Kind() == reflect.Ptr {
+		return obj
+	}
+	ptr := reflect.New(val.Type())
+	ptr.Elem().Set(val)
+	return ptr.Interface()
+}
+
+// TypedGoType is a GoType whose Create, Instantiate and Clone return T instead of interface{}
+type TypedGoType[T any] struct {
+	*GoType
+}
+
+// GoTypeFor gets the TypedGoType of T, example: reflection.GoTypeFor[UnitLocation]().Create(nil)
+func GoTypeFor[T any]() *TypedGoType[T] {
+	return &TypedGoType[T]{GoType: GoTypeOf(reflect.TypeOf((*T)(nil)).Elem())}
+}
+
+// Create is the same as GoType.Create, but returns T. If T is a pointer, a pointer to the created value is returned.
+func (typ *TypedGoType[T]) Create(obj interface{}) (T, error) {
+	res, err := typ.GoType.Create(obj)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return typ.typed(res), nil
+}
+
+// Instantiate is the same as Create, but it does not return an error. If an error occured, the zero value of T is returned.
+func (typ *TypedGoType[T]) Instantiate(obj interface{}) T {
+	res, _ := typ.Create(obj)
+	return res
+}
+
+// Clone is the same as GoType.Clone, but accepts and returns T
+func (typ *TypedGoType[T]) Clone(obj T) (T, error) {
+	res, err := typ.GoType.Clone(obj)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return typ.typed(res), nil
+}
+
+// typed converts a value returned by GoType into T, GoType.Create returns the value behind a pointer GoType
+func (typ *TypedGoType[T]) typed(val interface{}) T {
+	if res, ok := val.(T); ok {
+		return res
+	}
+	var zero T
+	if val == nil || !typ.IsPtr() || reflect.TypeOf(val) != typ.Type.Elem() {
+		return zero
+	}
+	ptr := reflect.New(typ.Type.Elem())
+	ptr.Elem().Set(reflect.ValueOf(val))
+	res, _ := ptr.Interface().(T)
+	return res
+}
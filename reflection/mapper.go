@@ -0,0 +1,266 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ConverterFunc converts from, which has the registered source type, into a value of the registered destination type
+type ConverterFunc func(from interface{}) (interface{}, error)
+
+// HookFunc is called before or after a member of the registered destination type is mapped. to is a pointer to the member
+type HookFunc func(from interface{}, to interface{}) error
+
+// Mapper maps values between structs. Custom converters and hooks can be registered to a Mapper.
+// A Mapper compiles a mapping plan for each pair of types once and caches it, so repeated calls skip the member lookups.
+// Register converters and hooks before using the Mapper from multiple goroutines.
+type Mapper struct {
+	// Options configures how members are copied
+	Options MapOptions
+	// Tracer receives an event for each member visited, converted, skipped or failed. Nil by default.
+	Tracer Tracer
+
+	converters  map[typePair]ConverterFunc
+	beforeHooks map[reflect.Type][]HookFunc
+	afterHooks  map[reflect.Type][]HookFunc
+
+	mu    sync.RWMutex
+	plans map[planKey]*mapPlan
+}
+
+type typePair struct {
+	from reflect.Type
+	to   reflect.Type
+}
+
+// DefaultMapper is the Mapper used by Map and MapSlice
+var DefaultMapper = NewMapper()
+
+// NewMapper creates a Mapper without converters and hooks
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// RegisterConverter registers a converter used whenever a value of type from is mapped to a value of type to
+func (m *Mapper) RegisterConverter(from reflect.Type, to reflect.Type, conv ConverterFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.converters == nil {
+		m.converters = map[typePair]ConverterFunc{}
+	}
+	m.converters[typePair{from, to}] = conv
+	m.plans = nil
+}
+
+// RegisterBeforeMap registers a hook called before a member of type to is mapped
+func (m *Mapper) RegisterBeforeMap(to reflect.Type, hook HookFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.beforeHooks == nil {
+		m.beforeHooks = map[reflect.Type][]HookFunc{}
+	}
+	m.beforeHooks[to] = append(m.beforeHooks[to], hook)
+	m.plans = nil
+}
+
+// RegisterAfterMap registers a hook called after a member of type to is mapped successfully
+func (m *Mapper) RegisterAfterMap(to reflect.Type, hook HookFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.afterHooks == nil {
+		m.afterHooks = map[reflect.Type][]HookFunc{}
+	}
+	m.afterHooks[to] = append(m.afterHooks[to], hook)
+	m.plans = nil
+}
+
+// Map maps all members of from to matching member of to. Both from and to must be pointers.
+func (m *Mapper) Map(from interface{}, to interface{}) error {
+	return m.mapWith(from, to, m.Options)
+}
+
+// MapSlice maps all element of from to slice to. Both from and to must be pointers to a slice.
+func (m *Mapper) MapSlice(from interface{}, to interface{}) error {
+	return m.mapSliceWith(from, to, m.Options)
+}
+
+func (m *Mapper) mapWith(from interface{}, to interface{}, opts MapOptions) error {
+	frVal, frTyp, frOK := GetType(from)
+	if !frOK {
+		return newMappingError("mapper.Map()", reflect.TypeOf(from), reflect.TypeOf(to), "from must be a pointer")
+	}
+	toVal, toTyp, toOK := GetType(to)
+	if !toOK {
+		return newMappingError("mapper.Map()", reflect.TypeOf(from), reflect.TypeOf(to), "to must be a pointer")
+	}
+	c := mapContext{mapper: m, options: opts, op: "mapper.Map()", tracer: m.Tracer}
+	return c.run(m.plan(frTyp, toTyp, opts), frVal, toVal)
+}
+
+func (m *Mapper) mapSliceWith(from interface{}, to interface{}, opts MapOptions) error {
+	op := "mapper.SliceMapper()"
+	frVal, frTyp, frOK := GetType(from)
+	if !frOK {
+		return newMappingError(op, reflect.TypeOf(from), reflect.TypeOf(to), "from must be a pointer")
+	}
+	toVal, toTyp, toOK := GetType(to)
+	if !toOK {
+		return newMappingError(op, reflect.TypeOf(from), reflect.TypeOf(to), "to must be a pointer")
+	}
+	if toTyp.Kind() != frTyp.Kind() {
+		return newMappingError(op, frTyp, toTyp, "from and to must be the same kind")
+	}
+	if frTyp.Kind() != reflect.Slice {
+		return newMappingError(op, frTyp, toTyp, "from and to must be a slice")
+	}
+	c := mapContext{mapper: m, options: opts, op: op, tracer: m.Tracer}
+	return c.run(m.plan(frTyp, toTyp, opts), frVal, toVal)
+}
+
+// mapContext holds the state of a single Map call
+type mapContext struct {
+	mapper  *Mapper
+	options MapOptions
+	op      string
+	tracer  Tracer
+	// errs collects the failures in lenient mode
+	errs MappingErrors
+	// path is the stack of members currently being mapped, it is only joined into a string when needed
+	path []pathSegment
+}
+
+// pathSegment is a field name, a slice index or a map key
+type pathSegment struct {
+	name  string
+	index int
+	key   reflect.Value
+}
+
+func (c *mapContext) pushField(name string) {
+	c.path = append(c.path, pathSegment{name: name})
+}
+
+func (c *mapContext) pushIndex(index int) {
+	c.path = append(c.path, pathSegment{index: index})
+}
+
+func (c *mapContext) pushKey(key reflect.Value) {
+	c.path = append(c.path, pathSegment{key: key})
+}
+
+func (c *mapContext) pop() {
+	c.path = c.path[:len(c.path)-1]
+}
+
+// pathString joins the current path, example: Orders[3].Customer.Email
+func (c *mapContext) pathString() string {
+	var sb strings.Builder
+	for _, seg := range c.path {
+		switch {
+		case seg.name != "":
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(seg.name)
+		case seg.key.IsValid():
+			sb.WriteString("[" + fmt.Sprint(seg.key.Interface()) + "]")
+		default:
+			sb.WriteString("[" + strconv.Itoa(seg.index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// run executes plan and returns the failures collected in lenient mode
+func (c *mapContext) run(plan *mapPlan, frVal reflect.Value, toVal reflect.Value) error {
+	err := plan.fn(c, frVal, toVal)
+	if err != nil {
+		return err
+	}
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+	return nil
+}
+
+// fail creates a MappingError for the member currently being mapped. In lenient mode the error is
+// collected and nil is returned, so mapping continues with the next member.
+func (c *mapContext) fail(from reflect.Type, to reflect.Type, err error) error {
+	mErr := &MappingError{Op: c.op, Path: c.pathString(), From: from, To: to, Err: err}
+	if c.tracer != nil {
+		c.tracer.Trace(TraceEvent{Kind: TraceFailed, Op: c.op, Path: mErr.Path, From: from, To: to, Err: err})
+	}
+	if c.options.Lenient {
+		c.errs = append(c.errs, mErr)
+		return nil
+	}
+	return mErr
+}
+
+// trace sends an event for the member currently being mapped
+func (c *mapContext) trace(kind TraceKind, from reflect.Type, to reflect.Type, msg string) {
+	c.tracer.Trace(TraceEvent{Kind: kind, Op: c.op, Path: c.pathString(), From: from, To: to, Message: msg})
+}
+
+// convertWith maps frVal into toVal using a registered converter
+func (c *mapContext) convertWith(conv ConverterFunc, frVal reflect.Value, toVal reflect.Value) error {
+	res, err := conv(frVal.Interface())
+	if err != nil {
+		return c.fail(frVal.Type(), toVal.Type(), err)
+	}
+	if res == nil {
+		toVal.Set(reflect.Zero(toVal.Type()))
+	} else if err := assignValue(reflect.ValueOf(res), toVal); err != nil {
+		return c.fail(frVal.Type(), toVal.Type(), errors.New("converter returned "+reflect.TypeOf(res).String()+" instead of "+toVal.Type().String()))
+	}
+	if c.tracer != nil {
+		c.trace(TraceConverted, frVal.Type(), toVal.Type(), "")
+	}
+	return nil
+}
+
+// mapDynamic maps frVal into toVal using the plan of their dynamic types
+func (c *mapContext) mapDynamic(frVal reflect.Value, toVal reflect.Value) error {
+	return c.mapper.plan(frVal.Type(), toVal.Type(), c.options).fn(c, frVal, toVal)
+}
+
+// mapInterface copies the concrete value frVal into the interface toVal
+func (c *mapContext) mapInterface(frVal reflect.Value, toVal reflect.Value) error {
+	if !frVal.Type().AssignableTo(toVal.Type()) {
+		return c.fail(frVal.Type(), toVal.Type(), errors.New(frVal.Type().String()+" does not implement "+toVal.Type().String()))
+	}
+	newVal := reflect.New(frVal.Type()).Elem()
+	err := c.mapDynamic(frVal, newVal)
+	if err != nil {
+		return err
+	}
+	toVal.Set(newVal)
+	return nil
+}
+
+// assignValue sets a scalar, channel or function value, converting between named types of the same kind
+func assignValue(frVal reflect.Value, toVal reflect.Value) error {
+	if frVal.Type().AssignableTo(toVal.Type()) {
+		toVal.Set(frVal)
+		return nil
+	}
+	if frVal.Type().ConvertibleTo(toVal.Type()) {
+		toVal.Set(frVal.Convert(toVal.Type()))
+		return nil
+	}
+	return errors.New("mapping is not supported for this type")
+}
+
+// isOpaqueStruct checks whether a struct type has no exported fields
+func isOpaqueStruct(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,433 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Benchmarks MapSlice against the Mapper before mapping plans were cached. The legacy Mapper below is that implementation
+// as it was, renamed so it can live next to the current one. Run with: go test -bench MapSlice -benchmem ./reflection
+
+type benchRow struct {
+	ID       int
+	Name     string
+	Email    string
+	Age      int
+	Score    float64
+	Active   bool
+	Country  string
+	Comments string
+}
+
+type benchRowDTO struct {
+	ID       int
+	Name     string
+	Email    string
+	Age      int
+	Score    float64
+	Active   bool
+	Country  string
+	Comments string
+}
+
+type benchAddress struct {
+	Street string
+	City   string
+}
+
+type benchOrder struct {
+	ID       int64
+	Customer string
+	Address  *benchAddress
+	Tags     []string
+	Lines    []benchRow
+	Created  time.Time
+}
+
+type benchOrderDTO struct {
+	ID       int64
+	Customer string `map:"Customer,required"`
+	City     string `map:"Address.City"`
+	Address  *benchAddress
+	Tags     []string
+	Lines    []benchRowDTO
+	Created  time.Time
+}
+
+func benchRows(n int) []benchRow {
+	rows := make([]benchRow, n)
+	for i := range rows {
+		rows[i] = benchRow{i, "Name " + strconv.Itoa(i), "name@example.com", 30, 9.5, true, "ID", "Lorem ipsum"}
+	}
+	return rows
+}
+
+func benchOrders(n int) []benchOrder {
+	orders := make([]benchOrder, n)
+	for i := range orders {
+		orders[i] = benchOrder{
+			ID:       int64(i),
+			Customer: "Customer " + strconv.Itoa(i),
+			Address:  &benchAddress{"Street", "City"},
+			Tags:     []string{"a", "b"},
+			Lines:    benchRows(3),
+			Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+	}
+	return orders
+}
+
+func BenchmarkMapSlice(b *testing.B) {
+	rows := benchRows(10000)
+	benchmarkMapSlice(b, &rows, func() interface{} { return &[]benchRowDTO{} })
+}
+
+func BenchmarkMapSliceNested(b *testing.B) {
+	orders := benchOrders(2000)
+	benchmarkMapSlice(b, &orders, func() interface{} { return &[]benchOrderDTO{} })
+}
+
+// benchmarkMapSlice checks that both implementations give the same result, then benchmarks each of them
+func benchmarkMapSlice(b *testing.B, from interface{}, newTo func() interface{}) {
+	legacy := &legacyMapper{}
+	want, got := newTo(), newTo()
+	if err := legacy.MapSlice(from, want); err != nil {
+		b.Fatal(err)
+	}
+	if err := MapSlice(from, got); err != nil {
+		b.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		b.Fatal("MapSlice and the legacy Mapper give different results")
+	}
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := legacy.MapSlice(from, newTo()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("planned", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := MapSlice(from, newTo()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// legacyMapper is the Mapper before mapping plans were cached, every member is resolved by name on each call
+type legacyMapper struct {
+	Options MapOptions
+
+	converters  map[typePair]ConverterFunc
+	beforeHooks map[reflect.Type][]HookFunc
+	afterHooks  map[reflect.Type][]HookFunc
+}
+
+func (m *legacyMapper) MapSlice(from interface{}, to interface{}) error {
+	frVal, frTyp, frOK := GetType(from)
+	if !frOK {
+		return errors.New("mapper.SliceMapper() - from must be a pointer")
+	}
+	toVal, toTyp, toOK := GetType(to)
+	if !toOK {
+		return errors.New("mapper.SliceMapper() - to must be a pointer")
+	}
+	if toTyp.Kind() != frTyp.Kind() {
+		return errors.New("mapper.SliceMapper() - from and to must be the same kind")
+	}
+	if frTyp.Kind() != reflect.Slice {
+		return errors.New("mapper.SliceMapper() - from and to must be a slice")
+	}
+	c := legacyContext{mapper: m}
+	return c.mapValue("", frVal, toVal)
+}
+
+type legacyContext struct {
+	mapper *legacyMapper
+}
+
+func (c *legacyContext) mapValue(path string, frVal reflect.Value, toVal reflect.Value) error {
+	m := c.mapper
+	toTyp := toVal.Type()
+	before := m.beforeHooks[toTyp]
+	after := m.afterHooks[toTyp]
+	if len(before) == 0 && len(after) == 0 {
+		return c.mapMember(path, frVal, toVal)
+	}
+	from := frVal.Interface()
+	for _, hook := range before {
+		err := hook(from, toVal.Addr().Interface())
+		if err != nil {
+			return legacyMapError(path, err)
+		}
+	}
+	err := c.mapMember(path, frVal, toVal)
+	if err != nil {
+		return err
+	}
+	for _, hook := range after {
+		err := hook(from, toVal.Addr().Interface())
+		if err != nil {
+			return legacyMapError(path, err)
+		}
+	}
+	return nil
+}
+
+func (c *legacyContext) mapMember(path string, frVal reflect.Value, toVal reflect.Value) error {
+	if conv, ok := c.mapper.converters[typePair{frVal.Type(), toVal.Type()}]; ok {
+		return c.convertWith(path, conv, frVal, toVal)
+	}
+	frKind := frVal.Kind()
+	toKind := toVal.Kind()
+	if frKind == reflect.Interface && toKind != reflect.Interface {
+		if frVal.IsNil() {
+			toVal.Set(reflect.Zero(toVal.Type()))
+			return nil
+		}
+		return c.mapValue(path, frVal.Elem(), toVal)
+	}
+	if toKind == reflect.Interface && frKind != reflect.Interface {
+		return c.mapInterface(path, frVal, toVal)
+	}
+	if c.mapper.Options.ConvertTypes {
+		if frKind == reflect.Ptr && toKind != reflect.Ptr {
+			if frVal.IsNil() {
+				toVal.Set(reflect.Zero(toVal.Type()))
+				return nil
+			}
+			return c.mapValue(path, frVal.Elem(), toVal)
+		}
+		if toKind == reflect.Ptr && frKind != reflect.Ptr {
+			if toVal.IsNil() {
+				toVal.Set(reflect.New(toVal.Type().Elem()))
+			}
+			return c.mapValue(path, frVal, toVal.Elem())
+		}
+		handled, err := convertValue(frVal, toVal, c.mapper.Options.TimeLayout)
+		if err != nil {
+			return legacyMapError(path, err)
+		}
+		if handled {
+			return nil
+		}
+	}
+	if frKind != toKind {
+		return legacyMapError(path, errors.New("from and to must be the same kind"))
+	}
+
+	switch frKind {
+	case reflect.Struct:
+		return c.mapStruct(path, frVal, toVal)
+	case reflect.Ptr:
+		if frVal.IsNil() {
+			toVal.Set(reflect.Zero(toVal.Type()))
+			return nil
+		}
+		if toVal.IsNil() {
+			toVal.Set(reflect.New(toVal.Type().Elem()))
+		}
+		return c.mapValue(path, frVal.Elem(), toVal.Elem())
+	case reflect.Interface:
+		if frVal.IsNil() {
+			toVal.Set(reflect.Zero(toVal.Type()))
+			return nil
+		}
+		return c.mapInterface(path, frVal.Elem(), toVal)
+	case reflect.Slice:
+		return c.mapSlice(path, frVal, toVal)
+	case reflect.Array:
+		for i := 0; i < frVal.Len() && i < toVal.Len(); i++ {
+			err := c.mapValue(legacyIndexPath(path, i), frVal.Index(i), toVal.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		return c.mapMap(path, frVal, toVal)
+	}
+	err := legacyAssignValue(frVal, toVal)
+	if err != nil {
+		return legacyMapError(path, err)
+	}
+	return nil
+}
+
+func (c *legacyContext) mapStruct(path string, frVal reflect.Value, toVal reflect.Value) error {
+	frTyp := frVal.Type()
+	toTyp := toVal.Type()
+	if frTyp == toTyp && isOpaqueStruct(toTyp) {
+		toVal.Set(frVal)
+		return nil
+	}
+	for i := 0; i < toTyp.NumField(); i++ {
+		fld := toTyp.Field(i)
+		tag := parseMapTag(fld)
+		if tag.Ignore || !toVal.Field(i).CanSet() {
+			continue
+		}
+		frFldVal, exists := legacySourceField(frVal, tag.Name)
+		if !exists && tag.Required {
+			return legacyMapError(legacyFieldPath(path, fld.Name), errors.New("required member "+tag.Name+" is not found in "+frTyp.String()))
+		}
+		if !frFldVal.IsValid() {
+			continue
+		}
+		err := c.mapValue(legacyFieldPath(path, fld.Name), frFldVal, toVal.Field(i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *legacyContext) mapSlice(path string, frVal reflect.Value, toVal reflect.Value) error {
+	if frVal.IsNil() {
+		toVal.Set(reflect.Zero(toVal.Type()))
+		return nil
+	}
+	l := frVal.Len()
+	cp := frVal.Cap()
+
+	newSlice := reflect.MakeSlice(toVal.Type(), l, cp)
+
+	for i := 0; i < l; i++ {
+		err := c.mapValue(legacyIndexPath(path, i), frVal.Index(i), newSlice.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+
+	toVal.Set(newSlice)
+
+	return nil
+}
+
+func (c *legacyContext) mapMap(path string, frVal reflect.Value, toVal reflect.Value) error {
+	if frVal.IsNil() {
+		toVal.Set(reflect.Zero(toVal.Type()))
+		return nil
+	}
+	toTyp := toVal.Type()
+	newMap := reflect.MakeMapWithSize(toTyp, frVal.Len())
+	iter := frVal.MapRange()
+	for iter.Next() {
+		elemPath := legacyKeyPath(path, iter.Key())
+		key := reflect.New(toTyp.Key()).Elem()
+		err := c.mapValue(elemPath, iter.Key(), key)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(toTyp.Elem()).Elem()
+		err = c.mapValue(elemPath, iter.Value(), elem)
+		if err != nil {
+			return err
+		}
+		newMap.SetMapIndex(key, elem)
+	}
+	toVal.Set(newMap)
+	return nil
+}
+
+func (c *legacyContext) convertWith(path string, conv ConverterFunc, frVal reflect.Value, toVal reflect.Value) error {
+	res, err := conv(frVal.Interface())
+	if err != nil {
+		return legacyMapError(path, err)
+	}
+	if res == nil {
+		toVal.Set(reflect.Zero(toVal.Type()))
+		return nil
+	}
+	err = legacyAssignValue(reflect.ValueOf(res), toVal)
+	if err != nil {
+		return legacyMapError(path, errors.New("converter returned "+reflect.TypeOf(res).String()+" instead of "+toVal.Type().String()))
+	}
+	return nil
+}
+
+func (c *legacyContext) mapInterface(path string, frVal reflect.Value, toVal reflect.Value) error {
+	newVal := reflect.New(frVal.Type()).Elem()
+	err := c.mapValue(path, frVal, newVal)
+	if err != nil {
+		return err
+	}
+	if !newVal.Type().AssignableTo(toVal.Type()) {
+		return legacyMapError(path, errors.New(newVal.Type().String()+" does not implement "+toVal.Type().String()))
+	}
+	toVal.Set(newVal)
+	return nil
+}
+
+// legacySourceField resolves the member of frVal by name on every call
+func legacySourceField(frVal reflect.Value, name string) (val reflect.Value, exists bool) {
+	val = frVal
+	for _, part := range strings.Split(name, ".") {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}, true
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		fld, found := lookupField(val.Type(), part)
+		if !found {
+			return reflect.Value{}, false
+		}
+		fldVal, err := val.FieldByIndexErr(fld.Index)
+		if err != nil {
+			return reflect.Value{}, true
+		}
+		val = fldVal
+	}
+	if !val.CanInterface() {
+		return reflect.Value{}, true
+	}
+	return val, true
+}
+
+func legacyAssignValue(frVal reflect.Value, toVal reflect.Value) error {
+	if frVal.Type().AssignableTo(toVal.Type()) {
+		toVal.Set(frVal)
+		return nil
+	}
+	if frVal.Type().ConvertibleTo(toVal.Type()) {
+		toVal.Set(frVal.Convert(toVal.Type()))
+		return nil
+	}
+	return errors.New("mapping is not supported for this type")
+}
+
+func legacyMapError(path string, err error) error {
+	if path == "" {
+		return errors.New("mapper.Map() - " + err.Error())
+	}
+	return errors.New("mapper.Map() - " + path + ": " + err.Error())
+}
+
+func legacyFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func legacyIndexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+func legacyKeyPath(path string, key reflect.Value) string {
+	return path + "[" + fmt.Sprint(key.Interface()) + "]"
+}
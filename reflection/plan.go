@@ -0,0 +1,380 @@
+package reflection
+
+import (
+	"errors"
+	"reflect"
+)
+
+// mapFunc maps frVal into toVal. Both values have the types the function was compiled for.
+type mapFunc func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error
+
+// mapPlan is a compiled mapping between two types
+type mapPlan struct {
+	fn mapFunc
+}
+
+type planKey struct {
+	from reflect.Type
+	to   reflect.Type
+	opts MapOptions
+}
+
+// fieldPlan maps a single field of a struct
+type fieldPlan struct {
+	// toIndex is the index of the destination field
+	toIndex int
+	// name is the name of the destination field
+	name string
+	// typ is the type of the destination field
+	typ reflect.Type
+	// srcTyp is the type of the source member
+	srcTyp reflect.Type
+	// source is the index path to the source member, pointers are dereferenced between each index
+	source [][]int
+	plan   *mapPlan
+	// err is returned when the field is mapped, it is set if a required member is not found in the source
+	err error
+	// skip is the reason the field is not mapped
+	skip string
+}
+
+// plan gets the cached plan mapping from into to, compiling it if it does not exist
+func (m *Mapper) plan(from reflect.Type, to reflect.Type, opts MapOptions) *mapPlan {
+	key := planKey{from, to, opts}
+	m.mu.RLock()
+	p := m.plans[key]
+	m.mu.RUnlock()
+	if p != nil {
+		return p
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.compile(key)
+}
+
+// compile creates the plan for key, m.mu must be locked.
+// The plan is cached before its members are compiled so recursive types refer to the same plan.
+func (m *Mapper) compile(key planKey) *mapPlan {
+	if p, ok := m.plans[key]; ok {
+		return p
+	}
+	if m.plans == nil {
+		m.plans = map[planKey]*mapPlan{}
+	}
+	p := &mapPlan{}
+	m.plans[key] = p
+	p.fn = m.withHooks(key.to, m.compileMember(key))
+	return p
+}
+
+// withHooks wraps fn with the hooks registered for type to
+func (m *Mapper) withHooks(to reflect.Type, fn mapFunc) mapFunc {
+	before := m.beforeHooks[to]
+	after := m.afterHooks[to]
+	if len(before) == 0 && len(after) == 0 {
+		return fn
+	}
+	return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+		from := frVal.Interface()
+		for _, hook := range before {
+			err := hook(from, toVal.Addr().Interface())
+			if err != nil {
+				return c.fail(frVal.Type(), to, err)
+			}
+		}
+		err := fn(c, frVal, toVal)
+		if err != nil {
+			return err
+		}
+		for _, hook := range after {
+			err := hook(from, toVal.Addr().Interface())
+			if err != nil {
+				return c.fail(frVal.Type(), to, err)
+			}
+		}
+		return nil
+	}
+}
+
+func (m *Mapper) compileMember(key planKey) mapFunc {
+	from, to, opts := key.from, key.to, key.opts
+	if conv, ok := m.converters[typePair{from, to}]; ok {
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			return c.convertWith(conv, frVal, toVal)
+		}
+	}
+	frKind := from.Kind()
+	toKind := to.Kind()
+	if frKind == reflect.Interface && toKind != reflect.Interface {
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			if frVal.IsNil() {
+				toVal.Set(reflect.Zero(to))
+				return nil
+			}
+			return c.mapDynamic(frVal.Elem(), toVal)
+		}
+	}
+	if toKind == reflect.Interface && frKind != reflect.Interface {
+		if !from.AssignableTo(to) {
+			return failFunc(from, to, errors.New(from.String()+" does not implement "+to.String()))
+		}
+		elem := m.compile(planKey{from, from, opts})
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			newVal := reflect.New(from).Elem()
+			err := elem.fn(c, frVal, newVal)
+			if err != nil {
+				return err
+			}
+			toVal.Set(newVal)
+			return nil
+		}
+	}
+	if opts.ConvertTypes {
+		if frKind == reflect.Ptr && toKind != reflect.Ptr {
+			elem := m.compile(planKey{from.Elem(), to, opts})
+			return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+				if frVal.IsNil() {
+					toVal.Set(reflect.Zero(to))
+					return nil
+				}
+				return elem.fn(c, frVal.Elem(), toVal)
+			}
+		}
+		if toKind == reflect.Ptr && frKind != reflect.Ptr {
+			elem := m.compile(planKey{from, to.Elem(), opts})
+			return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+				if toVal.IsNil() {
+					toVal.Set(reflect.New(to.Elem()))
+				}
+				return elem.fn(c, frVal, toVal.Elem())
+			}
+		}
+		if hasConversion(from, to) {
+			layout := opts.TimeLayout
+			return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+				_, err := convertValue(frVal, toVal, layout)
+				if err != nil {
+					return c.fail(from, to, err)
+				}
+				if c.tracer != nil {
+					c.trace(TraceConverted, from, to, "")
+				}
+				return nil
+			}
+		}
+	}
+	if frKind != toKind {
+		return failFunc(from, to, errors.New("from and to must be the same kind"))
+	}
+
+	switch frKind {
+	case reflect.Struct:
+		return m.compileStruct(from, to, opts)
+	case reflect.Ptr:
+		elem := m.compile(planKey{from.Elem(), to.Elem(), opts})
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			if frVal.IsNil() {
+				toVal.Set(reflect.Zero(to))
+				return nil
+			}
+			if toVal.IsNil() {
+				toVal.Set(reflect.New(to.Elem()))
+			}
+			return elem.fn(c, frVal.Elem(), toVal.Elem())
+		}
+	case reflect.Interface:
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			if frVal.IsNil() {
+				toVal.Set(reflect.Zero(to))
+				return nil
+			}
+			return c.mapInterface(frVal.Elem(), toVal)
+		}
+	case reflect.Slice:
+		return m.compileSlice(from, to, opts)
+	case reflect.Array:
+		elem := m.compile(planKey{from.Elem(), to.Elem(), opts})
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			for i := 0; i < frVal.Len() && i < toVal.Len(); i++ {
+				c.pushIndex(i)
+				err := elem.fn(c, frVal.Index(i), toVal.Index(i))
+				c.pop()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		return m.compileMap(from, to, opts)
+	}
+
+	if from.AssignableTo(to) {
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			toVal.Set(frVal)
+			return nil
+		}
+	}
+	if from.ConvertibleTo(to) {
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			toVal.Set(frVal.Convert(to))
+			return nil
+		}
+	}
+	return failFunc(from, to, errors.New("mapping is not supported for this type"))
+}
+
+func (m *Mapper) compileStruct(from reflect.Type, to reflect.Type, opts MapOptions) mapFunc {
+	if from == to && isOpaqueStruct(to) {
+		// structs without exported fields (like time.Time) can only be copied as a whole
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			toVal.Set(frVal)
+			return nil
+		}
+	}
+	fields := []fieldPlan{}
+	for i := 0; i < to.NumField(); i++ {
+		fld := to.Field(i)
+		tag := parseMapTag(fld)
+		if fld.PkgPath != "" {
+			fields = append(fields, fieldPlan{toIndex: i, name: fld.Name, typ: fld.Type, skip: "unexported field"})
+			continue
+		}
+		if tag.Ignore {
+			fields = append(fields, fieldPlan{toIndex: i, name: fld.Name, typ: fld.Type, skip: "ignored by tag"})
+			continue
+		}
+		source, srcTyp, exists := sourceFieldIndex(from, tag.Name)
+		if !exists {
+			if tag.Required {
+				fields = append(fields, fieldPlan{toIndex: i, name: fld.Name, typ: fld.Type, err: errors.New("required member " + tag.Name + " is not found in " + from.String())})
+			} else {
+				fields = append(fields, fieldPlan{toIndex: i, name: fld.Name, typ: fld.Type, skip: tag.Name + " is not found in " + from.String()})
+			}
+			continue
+		}
+		fields = append(fields, fieldPlan{toIndex: i, name: fld.Name, typ: fld.Type, srcTyp: srcTyp, source: source, plan: m.compile(planKey{srcTyp, fld.Type, opts})})
+	}
+	return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+		for i := range fields {
+			fp := &fields[i]
+			if fp.skip != "" {
+				if c.tracer != nil {
+					c.pushField(fp.name)
+					c.trace(TraceSkipped, nil, fp.typ, fp.skip)
+					c.pop()
+				}
+				continue
+			}
+			c.pushField(fp.name)
+			var err error
+			if fp.err != nil {
+				err = c.fail(from, fp.typ, fp.err)
+			} else if frFldVal := fieldByIndexPath(frVal, fp.source); frFldVal.IsValid() {
+				if c.tracer != nil {
+					c.trace(TraceVisited, fp.srcTyp, fp.typ, "")
+				}
+				err = fp.plan.fn(c, frFldVal, toVal.Field(fp.toIndex))
+			} else if c.tracer != nil {
+				c.trace(TraceSkipped, fp.srcTyp, fp.typ, "nil in source")
+			}
+			c.pop()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (m *Mapper) compileSlice(from reflect.Type, to reflect.Type, opts MapOptions) mapFunc {
+	if from == to && isPlainType(to.Elem()) && m.hooksFree(to.Elem()) {
+		// elements without references can be copied at once
+		return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+			if frVal.IsNil() {
+				toVal.Set(reflect.Zero(to))
+				return nil
+			}
+			newSlice := reflect.MakeSlice(to, frVal.Len(), frVal.Cap())
+			reflect.Copy(newSlice, frVal)
+			toVal.Set(newSlice)
+			return nil
+		}
+	}
+	elem := m.compile(planKey{from.Elem(), to.Elem(), opts})
+	return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+		if frVal.IsNil() {
+			toVal.Set(reflect.Zero(to))
+			return nil
+		}
+		l := frVal.Len()
+		newSlice := reflect.MakeSlice(to, l, frVal.Cap())
+		for i := 0; i < l; i++ {
+			c.pushIndex(i)
+			err := elem.fn(c, frVal.Index(i), newSlice.Index(i))
+			c.pop()
+			if err != nil {
+				return err
+			}
+		}
+		toVal.Set(newSlice)
+		return nil
+	}
+}
+
+func (m *Mapper) compileMap(from reflect.Type, to reflect.Type, opts MapOptions) mapFunc {
+	key := m.compile(planKey{from.Key(), to.Key(), opts})
+	elem := m.compile(planKey{from.Elem(), to.Elem(), opts})
+	return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+		if frVal.IsNil() {
+			toVal.Set(reflect.Zero(to))
+			return nil
+		}
+		newMap := reflect.MakeMapWithSize(to, frVal.Len())
+		iter := frVal.MapRange()
+		for iter.Next() {
+			c.pushKey(iter.Key())
+			newKey := reflect.New(to.Key()).Elem()
+			err := key.fn(c, iter.Key(), newKey)
+			if err != nil {
+				c.pop()
+				return err
+			}
+			newElem := reflect.New(to.Elem()).Elem()
+			err = elem.fn(c, iter.Value(), newElem)
+			c.pop()
+			if err != nil {
+				return err
+			}
+			newMap.SetMapIndex(newKey, newElem)
+		}
+		toVal.Set(newMap)
+		return nil
+	}
+}
+
+// hooksFree checks whether no converter or hook is registered for type typ
+func (m *Mapper) hooksFree(typ reflect.Type) bool {
+	if len(m.beforeHooks[typ]) > 0 || len(m.afterHooks[typ]) > 0 {
+		return false
+	}
+	_, ok := m.converters[typePair{typ, typ}]
+	return !ok
+}
+
+// isPlainType checks whether a value of typ can be copied without sharing references
+func isPlainType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	}
+	return false
+}
+
+func failFunc(from reflect.Type, to reflect.Type, err error) mapFunc {
+	return func(c *mapContext, frVal reflect.Value, toVal reflect.Value) error {
+		return c.fail(from, to, err)
+	}
+}
@@ -9,161 +9,64 @@ package reflection
 */
 
 import (
-	"errors"
-	"fmt"
 	"reflect"
-	"strconv"
 )
 
+// MapOptions configures how Map copies values between members
+type MapOptions struct {
+	// ConvertTypes enables conversion between members of different types, example: int64 -> int, string -> int, time.Time -> string
+	ConvertTypes bool
+	// TimeLayout is the layout used to convert time.Time from and to string. Defaults to time.RFC3339
+	TimeLayout string
+	// Lenient continues mapping after a member fails, all failures are returned as MappingErrors
+	Lenient bool
+}
+
+// Map maps all members of from to matching member of to using DefaultMapper. Both from and to must be pointers.
+// Pointers, maps, slices, arrays and interfaces are deep copied, nil pointers on to are allocated.
 func Map(from interface{}, to interface{}) error {
-	frVal, frTyp, frOK := GetType(from)
-	if !frOK {
-		return errors.New("mapper.Map() - from must be a pointer")
-	}
-	toVal, toTyp, toOK := GetType(to)
-	if !toOK {
-		return errors.New("mapper.Map() - to must be a pointer")
-	}
-	if toTyp.Kind() != frTyp.Kind() {
-		return errors.New("mapper.Map() - from and to must be the same kind")
-	}
-	kind := frTyp.Kind()
-	if kind == reflect.Struct {
-		for i := 0; i < toTyp.NumField(); i++ {
-			fldName := toTyp.Field(i).Name
-			_, found := frTyp.FieldByName(fldName)
-			if found && toVal.FieldByName(fldName).CanSet() {
-				if frVal.FieldByName(fldName).CanAddr() && toVal.FieldByName(fldName).CanAddr() {
-					err := Map(frVal.FieldByName(fldName).Addr().Interface(), toVal.FieldByName(fldName).Addr().Interface())
-					if err != nil {
-						return err
-					}
-				} else {
-					err := Map(frVal.FieldByName(fldName).Interface(), toVal.FieldByName(fldName).Interface())
-					if err != nil {
-						return err
-					}
-				}
-			}
-		}
-		return nil
-	}
-	if toVal.CanSet() {
-		if kind == reflect.Int || kind == reflect.String || kind == reflect.Uint || kind == reflect.Float64 || kind == reflect.Float32 || kind == reflect.Bool {
-			toVal.Set(frVal)
-			return nil
-		}
-		if kind == reflect.Slice {
-			return MapSlice(from, to)
-		}
-	}
+	return DefaultMapper.Map(from, to)
+}
 
-	return errors.New("mapper.Map() - mapping is not supported for this type")
+// MapWithOptions is the same as Map, but uses the specified options instead of DefaultMapper.Options
+func MapWithOptions(from interface{}, to interface{}, opts MapOptions) error {
+	return DefaultMapper.mapWith(from, to, opts)
 }
 
+// MapSlice maps all element of from to slice to using DefaultMapper. Both from and to must be pointers to a slice.
 func MapSlice(from interface{}, to interface{}) error {
-	frVal, frTyp, frOK := GetType(from)
-	if !frOK {
-		return errors.New("mapper.SliceMapper() - from must be a pointer")
-	}
-	toVal, toTyp, toOK := GetType(to)
-	if !toOK {
-		return errors.New("mapper.SliceMapper() - to must be a pointer")
-	}
-	if toTyp.Kind() != frTyp.Kind() {
-		return errors.New("mapper.SliceMapper() - from and to must be the same kind")
-	}
-	if frTyp.Kind() != reflect.Slice {
-		return errors.New("mapper.SliceMapper() - from and to must be a slice")
-	}
-
-	l := frVal.Len()
-	c := frVal.Cap()
-
-	newSlice := reflect.MakeSlice(toTyp, l, c)
-
-	for i := 0; i < l; i++ {
-		if frVal.Index(i).CanAddr() && newSlice.Index(i).CanAddr() {
-			err := Map(frVal.Index(i).Addr().Interface(), newSlice.Index(i).Addr().Interface())
-			if err != nil {
-				return err
-			}
-		} else {
-			err := Map(frVal.Index(i).Interface(), newSlice.Index(i).Interface())
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	toVal.Set(newSlice)
+	return DefaultMapper.MapSlice(from, to)
+}
 
-	return nil
+// MapSliceWithOptions is the same as MapSlice, but uses the specified options instead of DefaultMapper.Options
+func MapSliceWithOptions(from interface{}, to interface{}, opts MapOptions) error {
+	return DefaultMapper.mapSliceWith(from, to, opts)
 }
 
 func GetType(obj interface{}) (val reflect.Value, typ reflect.Type, ok bool) {
 	otyp := reflect.TypeOf(obj)
-	kind := otyp.Kind()
-	if kind != reflect.Ptr {
+	if otyp == nil || otyp.Kind() != reflect.Ptr {
+		return reflect.ValueOf(nil), reflect.TypeOf(nil), false
+	}
+	if reflect.ValueOf(obj).IsNil() {
 		return reflect.ValueOf(nil), reflect.TypeOf(nil), false
 	}
 	oval := reflect.ValueOf(obj).Elem()
-	kind = oval.Kind()
 	otyp = oval.Type()
 	return oval, otyp, true
 }
 
-func ToString(value interface{}) string {
-	if value == nil {
-		return "null"
-	}
-	val, typ, ok := GetType(&value)
-
-	if !ok {
-		return "unknown{}"
-	}
-
-	var kind = val.Kind()
-	if kind == reflect.Int || kind == reflect.Int16 || kind == reflect.Int32 || kind == reflect.Int64 || kind == reflect.Int8 {
-		return strconv.FormatInt(val.Int(), 10)
-	}
-	if kind == reflect.Uint || kind == reflect.Uint16 || kind == reflect.Uint32 || kind == reflect.Uint64 || kind == reflect.Uint8 {
-		return strconv.FormatUint(val.Uint(), 10)
-	}
-	if kind == reflect.Float32 || kind == reflect.Float64 {
-		return strconv.FormatFloat(val.Float(), 'f', -1, 64)
-	}
-	if kind == reflect.String {
-		return val.String()
-	}
-	if kind == reflect.Array {
-		return "array[" + typ.Name() + "]"
-	}
-	if kind == reflect.Slice {
-		return "slice[" + typ.Name() + "]"
-	}
-	if kind == reflect.Struct {
-		return "struct{" + typ.Name() + "}"
-	}
-	if kind == reflect.Bool {
-		if val.Bool() {
-			return "true"
-		}
-		return "false"
-	}
-
-	return "object{" + typ.Name() + "}"
-}
-
 type GoType struct {
 	Kind reflect.Kind
 	Type reflect.Type
+	// UseDefaults makes Create apply the default tags of a struct to fields which are still zero, see ApplyDefaults
+	UseDefaults bool
 }
 
 // Create creates a new instance of the current GoType initialized to specified obj. If obj is nil, zero values is returned. References are passed as is.
 func (typ *GoType) Create(obj interface{}) (interface{}, error) {
 	if typ.IsPtrToPtr() {
-		return nil, errors.New("Pointer of a pointer is not supported")
+		return nil, newMappingError("GoType.Create()", reflect.TypeOf(obj), typ.Type, "Pointer of a pointer is not supported")
 	}
 	if obj == nil {
 		var ptrRes reflect.Value
@@ -172,11 +75,14 @@ func (typ *GoType) Create(obj interface{}) (interface{}, error) {
 		} else {
 			ptrRes = reflect.New(typ.Type)
 		}
+		if err := typ.applyDefaults(ptrRes); err != nil {
+			return nil, err
+		}
 		return ptrRes.Elem().Interface(), nil
 	}
 	t := GetGoType(obj)
 	if t.IsPtrToPtr() {
-		return nil, errors.New("Pointer of a pointer is not supported")
+		return nil, newMappingError("GoType.Create()", t.Type, typ.Type, "Pointer of a pointer is not supported")
 	}
 	v := reflect.ValueOf(obj)
 	if t.IsPtr() {
@@ -192,9 +98,20 @@ func (typ *GoType) Create(obj interface{}) (interface{}, error) {
 	if e != nil {
 		return nil, e
 	}
+	if e = typ.applyDefaults(ptrRes); e != nil {
+		return nil, e
+	}
 	return ptrRes.Elem().Interface(), nil
 }
 
+// applyDefaults applies the default tags to the struct pointed by ptrRes if UseDefaults is set
+func (typ *GoType) applyDefaults(ptrRes reflect.Value) error {
+	if !typ.UseDefaults || ptrRes.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return ApplyDefaults(ptrRes.Interface())
+}
+
 // Instantiate is the same as Create, but it does not return an error. If an error occured, nil is returned.
 func (typ *GoType) Instantiate(obj interface{}) interface{} {
 	val, err := typ.Create(obj)
@@ -247,12 +164,15 @@ func GoTypeOf(rtype reflect.Type) *GoType {
 	return &typ
 }
 
-// Assign will assigns a value to a pointer of a value
+// Assign will assigns a value to a pointer of a value. Events are sent to DefaultMapper.Tracer if it is set.
 func Assign(valFrom reflect.Value, ptrTo reflect.Value) error {
+	op := "reflection.Assign()"
+	tracer := DefaultMapper.Tracer
 	if valFrom.Kind() != ptrTo.Elem().Kind() {
-		return errors.New("Cannot assign a " + KindToString(valFrom.Kind()) + " to a " + KindToString(ptrTo.Elem().Kind()))
+		err := newMappingError(op, valFrom.Type(), ptrTo.Elem().Type(), "Cannot assign a "+KindToString(valFrom.Kind())+" to a "+KindToString(ptrTo.Elem().Kind()))
+		traceError(tracer, err)
+		return err
 	}
-	fmt.Println(KindToString(valFrom.Kind()) + " to a " + KindToString(ptrTo.Elem().Kind()))
 	var kind = valFrom.Kind()
 	valTo := ptrTo.Elem()
 
@@ -260,11 +180,37 @@ func Assign(valFrom reflect.Value, ptrTo reflect.Value) error {
 		toType := valTo.Type()
 		frType := valFrom.Type()
 		for i := 0; i < toType.NumField(); i++ {
-			fldName := toType.Field(i).Name
-			_, found := frType.FieldByName(fldName)
-			if found && valTo.FieldByName(fldName).CanSet() {
-				valTo.FieldByName(fldName).Set(valFrom.FieldByName(fldName))
+			fld := toType.Field(i)
+			tag := parseMapTag(fld)
+			if tag.Ignore || !valTo.Field(i).CanSet() {
+				if tracer != nil {
+					tracer.Trace(TraceEvent{Kind: TraceSkipped, Op: op, Path: fld.Name, To: fld.Type, Message: "ignored or unexported field"})
+				}
+				continue
+			}
+			fldFrom, exists := sourceField(valFrom, tag.Name)
+			if !exists && tag.Required {
+				err := newMappingError(op, frType, fld.Type, "Required member "+tag.Name+" is not found in "+frType.String())
+				err.Path = fld.Name
+				traceError(tracer, err)
+				return err
+			}
+			if !fldFrom.IsValid() {
+				if tracer != nil {
+					tracer.Trace(TraceEvent{Kind: TraceSkipped, Op: op, Path: fld.Name, To: fld.Type, Message: tag.Name + " is not found in " + frType.String()})
+				}
+				continue
+			}
+			if !fldFrom.Type().AssignableTo(fld.Type) {
+				err := newMappingError(op, fldFrom.Type(), fld.Type, "Cannot assign "+fldFrom.Type().String()+" to "+fld.Type.String())
+				err.Path = fld.Name
+				traceError(tracer, err)
+				return err
+			}
+			if tracer != nil {
+				tracer.Trace(TraceEvent{Kind: TraceVisited, Op: op, Path: fld.Name, From: fldFrom.Type(), To: fld.Type})
 			}
+			valTo.Field(i).Set(fldFrom)
 		}
 	}
 
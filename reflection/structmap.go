@@ -0,0 +1,361 @@
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var mapType = reflect.TypeOf(map[string]interface{}{})
+
+// ToMap converts the struct obj, or a pointer to it, into a map keyed by field name. Fields follow the map tags like Map,
+// a dotted tag name creates nested maps and an embedded struct without tag is flattened into its parent.
+// Nested structs are converted into maps, slices and arrays into []interface{} and maps into map[string]interface{}.
+// Structs without exported fields like time.Time are kept as is.
+func ToMap(obj interface{}) (map[string]interface{}, error) {
+	op := "reflection.ToMap()"
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, newMappingError(op, reflect.TypeOf(obj), mapType, "obj must be a struct or a pointer to a struct")
+	}
+	s := newStructMapper(op)
+	res, err := s.toMap(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.errs) > 0 {
+		return res, s.errs
+	}
+	return res, nil
+}
+
+// FromMap fills the struct pointed by ptrTo from m, the reverse of ToMap. Nested maps fill nested structs and slices fill
+// slices and arrays, nil pointers are allocated. Values of a different type are converted like MapOptions.ConvertTypes,
+// example: a float64 decoded from JSON into an int field. Keys without a matching field are ignored.
+func FromMap(m map[string]interface{}, ptrTo interface{}) error {
+	op := "reflection.FromMap()"
+	toVal, toTyp, ok := GetType(ptrTo)
+	if !ok {
+		return newMappingError(op, mapType, reflect.TypeOf(ptrTo), "ptrTo must be a pointer")
+	}
+	if toTyp.Kind() != reflect.Struct {
+		return newMappingError(op, mapType, toTyp, "ptrTo must be a pointer to a struct")
+	}
+	s := newStructMapper(op)
+	err := s.fromMap(reflect.ValueOf(m), toVal)
+	if err != nil {
+		return err
+	}
+	if len(s.errs) > 0 {
+		return s.errs
+	}
+	return nil
+}
+
+// structMapper converts between structs and maps, it uses the options and tracer of DefaultMapper
+type structMapper struct {
+	mapContext
+	// visiting contains the pointers and maps currently being converted, to detect cycles
+	visiting map[visitKey]bool
+}
+
+func newStructMapper(op string) *structMapper {
+	opts := DefaultMapper.Options
+	opts.ConvertTypes = true
+	return &structMapper{
+		mapContext: mapContext{mapper: DefaultMapper, options: opts, op: op, tracer: DefaultMapper.Tracer},
+		visiting:   map[visitKey]bool{},
+	}
+}
+
+// isEmbeddedStruct checks whether fld is an embedded struct which is flattened into its parent
+func isEmbeddedStruct(fld reflect.StructField, tag mapTag) bool {
+	return fld.Anonymous && !tag.Renamed && fld.Type.Kind() == reflect.Struct
+}
+
+func (s *structMapper) toMap(val reflect.Value) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	return res, s.fillMap(res, val)
+}
+
+func (s *structMapper) fillMap(res map[string]interface{}, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		tag := parseMapTag(fld)
+		if fld.PkgPath != "" || tag.Ignore {
+			continue
+		}
+		if isEmbeddedStruct(fld, tag) {
+			err := s.fillMap(res, val.Field(i))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		s.pushField(fld.Name)
+		fldVal, err := s.toValue(val.Field(i))
+		if err == nil {
+			err = s.setKey(res, tag.Name, fldVal)
+		}
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setKey sets the dotted key name of m, creating the nested maps
+func (s *structMapper) setKey(m map[string]interface{}, name string, val interface{}) error {
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			if _, exists := m[part]; exists {
+				return s.fail(reflect.TypeOf(val), mapType, errors.New("key "+part+" of "+name+" is not a map"))
+			}
+			next = map[string]interface{}{}
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = val
+	return nil
+}
+
+func (s *structMapper) toValue(val reflect.Value) (interface{}, error) {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if val.IsNil() {
+			return nil, nil
+		}
+	}
+	switch val.Kind() {
+	case reflect.Interface:
+		return s.toValue(val.Elem())
+	case reflect.Ptr:
+		if !s.enter(val) {
+			return nil, s.fail(val.Type(), nil, errors.New("cycle detected"))
+		}
+		defer s.leave(val)
+		return s.toValue(val.Elem())
+	case reflect.Struct:
+		if isOpaqueStruct(val.Type()) {
+			return val.Interface(), nil
+		}
+		return s.toMap(val)
+	case reflect.Slice, reflect.Array:
+		res := make([]interface{}, val.Len())
+		for i := range res {
+			s.pushIndex(i)
+			elem, err := s.toValue(val.Index(i))
+			s.pop()
+			if err != nil {
+				return nil, err
+			}
+			res[i] = elem
+		}
+		return res, nil
+	case reflect.Map:
+		if !s.enter(val) {
+			return nil, s.fail(val.Type(), mapType, errors.New("cycle detected"))
+		}
+		defer s.leave(val)
+		res := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			s.pushKey(iter.Key())
+			elem, err := s.toValue(iter.Value())
+			s.pop()
+			if err != nil {
+				return nil, err
+			}
+			res[keyString(iter.Key())] = elem
+		}
+		return res, nil
+	}
+	return val.Interface(), nil
+}
+
+func (s *structMapper) enter(val reflect.Value) bool {
+	key := visitKey{val.Pointer(), val.Type()}
+	if s.visiting[key] {
+		return false
+	}
+	s.visiting[key] = true
+	return true
+}
+
+func (s *structMapper) leave(val reflect.Value) {
+	delete(s.visiting, visitKey{val.Pointer(), val.Type()})
+}
+
+// keyString converts a map key into a string key
+func keyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if str, ok := formatValue(key); ok {
+		return str
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// fromMap fills the struct toVal from the map frVal, which must have string keys
+func (s *structMapper) fromMap(frVal reflect.Value, toVal reflect.Value) error {
+	typ := toVal.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		tag := parseMapTag(fld)
+		if fld.PkgPath != "" || tag.Ignore {
+			continue
+		}
+		if isEmbeddedStruct(fld, tag) {
+			err := s.fromMap(frVal, toVal.Field(i))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		val, found := lookupKey(frVal, tag.Name)
+		s.pushField(fld.Name)
+		var err error
+		if found {
+			err = s.fromValue(val, toVal.Field(i))
+		} else if tag.Required {
+			err = s.fail(nil, fld.Type, errors.New("required key "+tag.Name+" is not found"))
+		}
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupKey gets the value of m at name, either as a key or as a dotted path of nested maps
+func lookupKey(m reflect.Value, name string) (reflect.Value, bool) {
+	if val, ok := mapIndex(m, name); ok {
+		return val, true
+	}
+	if !strings.Contains(name, ".") {
+		return reflect.Value{}, false
+	}
+	val := m
+	for _, part := range strings.Split(name, ".") {
+		var ok bool
+		val, ok = mapIndex(val, part)
+		if !ok {
+			return reflect.Value{}, false
+		}
+	}
+	return val, true
+}
+
+// mapIndex gets key of m if m is a map with string keys
+func mapIndex(m reflect.Value, key string) (reflect.Value, bool) {
+	for m.Kind() == reflect.Interface && !m.IsNil() {
+		m = m.Elem()
+	}
+	if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, false
+	}
+	val := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+	return val, val.IsValid()
+}
+
+// fromValue sets toVal from the map value val
+func (s *structMapper) fromValue(val reflect.Value, toVal reflect.Value) error {
+	for val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if val.IsNil() {
+			toVal.Set(reflect.Zero(toVal.Type()))
+			return nil
+		}
+	}
+	if val.Type().AssignableTo(toVal.Type()) {
+		toVal.Set(val)
+		return nil
+	}
+
+	switch toVal.Kind() {
+	case reflect.Ptr:
+		if toVal.IsNil() {
+			toVal.Set(reflect.New(toVal.Type().Elem()))
+		}
+		return s.fromValue(val, toVal.Elem())
+	case reflect.Struct:
+		if val.Kind() == reflect.Map && !isOpaqueStruct(toVal.Type()) {
+			return s.fromMap(val, toVal)
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+			return s.fromList(val, toVal)
+		}
+	case reflect.Map:
+		if val.Kind() == reflect.Map {
+			return s.fromMapValue(val, toVal)
+		}
+	}
+
+	ok, err := convertValue(val, toVal, s.options.TimeLayout)
+	if err != nil {
+		return s.fail(val.Type(), toVal.Type(), err)
+	}
+	if ok {
+		return nil
+	}
+	if val.Kind() == toVal.Kind() && val.Type().ConvertibleTo(toVal.Type()) {
+		toVal.Set(val.Convert(toVal.Type()))
+		return nil
+	}
+	return s.fail(val.Type(), toVal.Type(), errors.New("cannot convert "+val.Type().String()+" to "+toVal.Type().String()))
+}
+
+func (s *structMapper) fromList(val reflect.Value, toVal reflect.Value) error {
+	count := val.Len()
+	if toVal.Kind() == reflect.Slice {
+		toVal.Set(reflect.MakeSlice(toVal.Type(), count, count))
+	} else if count > toVal.Len() {
+		count = toVal.Len()
+	}
+	for i := 0; i < count; i++ {
+		s.pushIndex(i)
+		err := s.fromValue(val.Index(i), toVal.Index(i))
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *structMapper) fromMapValue(val reflect.Value, toVal reflect.Value) error {
+	typ := toVal.Type()
+	res := reflect.MakeMapWithSize(typ, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		key := reflect.New(typ.Key()).Elem()
+		elem := reflect.New(typ.Elem()).Elem()
+		s.pushKey(iter.Key())
+		err := s.fromValue(iter.Key(), key)
+		if err == nil {
+			err = s.fromValue(iter.Value(), elem)
+		}
+		s.pop()
+		if err != nil {
+			return err
+		}
+		res.SetMapIndex(key, elem)
+	}
+	toVal.Set(res)
+	return nil
+}
@@ -0,0 +1,114 @@
+package reflection
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MapTagName is the struct tag key read by Map and Assign, example: `map:"Address.City,required"`
+const MapTagName = "map"
+
+// mapTag is the parsed value of a map struct tag
+type mapTag struct {
+	// Name is the name of the member on the other side, it can be a dotted path to flatten nested structs
+	Name string
+	// Ignore is true if the tag is "-"
+	Ignore bool
+	// Required is true if the member must exist in the source
+	Required bool
+	// Renamed is true if the tag specifies a name
+	Renamed bool
+}
+
+func parseMapTag(fld reflect.StructField) mapTag {
+	tag := mapTag{Name: fld.Name}
+	val, ok := fld.Tag.Lookup(MapTagName)
+	if !ok {
+		return tag
+	}
+	if val == "-" {
+		tag.Ignore = true
+		return tag
+	}
+	spl := strings.Split(val, ",")
+	name := strings.TrimSpace(spl[0])
+	if name != "" {
+		tag.Name = name
+		tag.Renamed = true
+	}
+	for _, opt := range spl[1:] {
+		if strings.TrimSpace(opt) == "required" {
+			tag.Required = true
+		}
+	}
+	return tag
+}
+
+// lookupField finds a field of struct typ which is known as name, either by its map tag or by its name
+func lookupField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		tag := parseMapTag(fld)
+		if tag.Renamed && !tag.Ignore && tag.Name == name {
+			return fld, true
+		}
+	}
+	fld, found := typ.FieldByName(name)
+	if !found || parseMapTag(fld).Ignore {
+		return fld, false
+	}
+	return fld, true
+}
+
+// sourceFieldIndex resolves the member of struct typ at the dotted path name into the index path of each part.
+// exists is false if the member is not declared in typ.
+func sourceFieldIndex(typ reflect.Type, name string) (index [][]int, fldTyp reflect.Type, exists bool) {
+	fldTyp = typ
+	for _, part := range strings.Split(name, ".") {
+		for fldTyp.Kind() == reflect.Ptr {
+			fldTyp = fldTyp.Elem()
+		}
+		if fldTyp.Kind() != reflect.Struct {
+			return nil, nil, false
+		}
+		fld, found := lookupField(fldTyp, part)
+		if !found {
+			return nil, nil, false
+		}
+		index = append(index, fld.Index)
+		fldTyp = fld.Type
+	}
+	return index, fldTyp, true
+}
+
+// fieldByIndexPath gets the member of val at an index path created by sourceFieldIndex.
+// The returned value is invalid if a nil pointer is found along the path or the member cannot be read.
+func fieldByIndexPath(val reflect.Value, index [][]int) reflect.Value {
+	for _, idx := range index {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}
+			}
+			val = val.Elem()
+		}
+		fldVal, err := val.FieldByIndexErr(idx)
+		if err != nil {
+			return reflect.Value{}
+		}
+		val = fldVal
+	}
+	if !val.CanInterface() {
+		return reflect.Value{}
+	}
+	return val
+}
+
+// sourceField gets the member of struct frVal at the dotted path name. exists is false if the member
+// is not declared in the source type, the returned value is invalid if a nil pointer is found along the path.
+func sourceField(frVal reflect.Value, name string) (val reflect.Value, exists bool) {
+	index, _, exists := sourceFieldIndex(frVal.Type(), name)
+	if !exists {
+		return reflect.Value{}, false
+	}
+	return fieldByIndexPath(frVal, index), true
+}
@@ -0,0 +1,241 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrintTagName is the struct tag key read by ToString, `print:"-"` omits a field and `print:"redact"` redacts its value
+const PrintTagName = "print"
+
+// PrintOptions configures ToStringWithOptions
+type PrintOptions struct {
+	// Indent indents each member of a struct, slice, array or map on its own line. Values are printed in a single line if Indent is empty
+	Indent string
+	// MaxDepth is how deep nested values are printed, deeper values are printed as ... (0 means unlimited)
+	MaxDepth int
+	// MaxWidth is how many elements of a slice, array or map and fields of a struct are printed (0 means unlimited)
+	MaxWidth int
+	// ShowRedacted prints the value of fields tagged with `print:"redact"`, they are printed as *** by default
+	ShowRedacted bool
+}
+
+// ToString prints value in a single line, including the fields of structs, elements of slices, arrays and maps and the value behind a pointer.
+// Nil is printed as null. Fields tagged with `print:"redact"` are redacted.
+func ToString(value interface{}) string {
+	return ToStringWithOptions(value, PrintOptions{})
+}
+
+// ToStringWithOptions is the same as ToString, but uses the specified options
+func ToStringWithOptions(value interface{}, opts PrintOptions) string {
+	if value == nil {
+		return "null"
+	}
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.String {
+		return val.String()
+	}
+	p := printer{opts: opts, visiting: map[visitKey]bool{}}
+	p.print(val, 0)
+	return p.sb.String()
+}
+
+type printer struct {
+	opts PrintOptions
+	sb   strings.Builder
+	// visiting contains the references currently being printed, to detect cycles
+	visiting map[visitKey]bool
+}
+
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (p *printer) print(val reflect.Value, depth int) {
+	if !val.IsValid() {
+		p.sb.WriteString("null")
+		return
+	}
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if val.IsNil() {
+			p.sb.WriteString("null")
+			return
+		}
+	}
+	if val.CanInterface() && val.Kind() != reflect.Interface {
+		switch v := val.Interface().(type) {
+		case error:
+			p.sb.WriteString(v.Error())
+			return
+		case fmt.Stringer:
+			p.sb.WriteString(v.String())
+			return
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		p.sb.WriteString(strconv.FormatBool(val.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		p.sb.WriteString(numberString(val))
+	case reflect.Complex64, reflect.Complex128:
+		p.sb.WriteString(strconv.FormatComplex(val.Complex(), 'f', -1, 128))
+	case reflect.String:
+		p.sb.WriteString(strconv.Quote(val.String()))
+	case reflect.Interface:
+		p.print(val.Elem(), depth)
+	case reflect.Ptr:
+		if p.enter(val) {
+			p.sb.WriteString("&")
+			p.print(val.Elem(), depth)
+			p.leave(val)
+		}
+	case reflect.Struct:
+		p.printStruct(val, depth)
+	case reflect.Slice:
+		if p.enter(val) {
+			p.printList("[", "]", val, depth)
+			p.leave(val)
+		}
+	case reflect.Array:
+		p.printList("[", "]", val, depth)
+	case reflect.Map:
+		if p.enter(val) {
+			p.printMap(val, depth)
+			p.leave(val)
+		}
+	default:
+		p.sb.WriteString("<" + val.Type().String() + ">")
+	}
+}
+
+// enter marks a reference as being printed, returns false and prints <cycle> if it is already being printed
+func (p *printer) enter(val reflect.Value) bool {
+	key := visitKey{val.Pointer(), val.Type()}
+	if p.visiting[key] {
+		p.sb.WriteString("<cycle>")
+		return false
+	}
+	p.visiting[key] = true
+	return true
+}
+
+func (p *printer) leave(val reflect.Value) {
+	delete(p.visiting, visitKey{val.Pointer(), val.Type()})
+}
+
+func (p *printer) printStruct(val reflect.Value, depth int) {
+	typ := val.Type()
+	if typ.Name() != "" {
+		p.sb.WriteString(typ.Name())
+	}
+	fields := []int{}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Tag.Get(PrintTagName) != "-" {
+			fields = append(fields, i)
+		}
+	}
+	p.printItems("{", "}", len(fields), depth, func(i int) {
+		fld := typ.Field(fields[i])
+		p.sb.WriteString(fld.Name + ": ")
+		if !p.opts.ShowRedacted && fld.Tag.Get(PrintTagName) == "redact" {
+			p.sb.WriteString("***")
+			return
+		}
+		p.print(val.Field(fields[i]), depth+1)
+	})
+}
+
+func (p *printer) printList(open string, close string, val reflect.Value, depth int) {
+	p.printItems(open, close, val.Len(), depth, func(i int) {
+		p.print(val.Index(i), depth+1)
+	})
+}
+
+func (p *printer) printMap(val reflect.Value, depth int) {
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+	p.sb.WriteString("map")
+	p.printItems("[", "]", len(keys), depth, func(i int) {
+		p.print(keys[i], depth+1)
+		p.sb.WriteString(": ")
+		p.print(val.MapIndex(keys[i]), depth+1)
+	})
+}
+
+// printItems prints count items between open and close, limited by MaxDepth and MaxWidth
+func (p *printer) printItems(open string, close string, count int, depth int, item func(i int)) {
+	p.sb.WriteString(open)
+	if count == 0 {
+		p.sb.WriteString(close)
+		return
+	}
+	if p.opts.MaxDepth > 0 && depth >= p.opts.MaxDepth {
+		p.sb.WriteString("..." + close)
+		return
+	}
+	shown := count
+	if p.opts.MaxWidth > 0 && shown > p.opts.MaxWidth {
+		shown = p.opts.MaxWidth
+	}
+	for i := 0; i < shown; i++ {
+		p.separate(i, depth)
+		item(i)
+	}
+	if shown < count {
+		p.separate(shown, depth)
+		p.sb.WriteString("... " + strconv.Itoa(count-shown) + " more")
+	}
+	if p.opts.Indent != "" {
+		p.sb.WriteString(",\n" + strings.Repeat(p.opts.Indent, depth))
+	}
+	p.sb.WriteString(close)
+}
+
+// separate writes the separator before the i-th item
+func (p *printer) separate(i int, depth int) {
+	if p.opts.Indent != "" {
+		if i > 0 {
+			p.sb.WriteString(",")
+		}
+		p.sb.WriteString("\n" + strings.Repeat(p.opts.Indent, depth+1))
+		return
+	}
+	if i > 0 {
+		p.sb.WriteString(", ")
+	}
+}
+
+// lessValue orders map keys, numbers and strings are ordered by their value and other kinds by their printed value
+func lessValue(a reflect.Value, b reflect.Value) bool {
+	kind := a.Kind()
+	switch {
+	case kind != b.Kind():
+	case isIntKind(kind):
+		return a.Int() < b.Int()
+	case isUintKind(kind):
+		return a.Uint() < b.Uint()
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return a.Float() < b.Float()
+	case kind == reflect.String:
+		return a.String() < b.String()
+	case kind == reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return printValue(a) < printValue(b)
+}
+
+func printValue(val reflect.Value) string {
+	p := printer{visiting: map[visitKey]bool{}}
+	p.print(val, 0)
+	return p.sb.String()
+}
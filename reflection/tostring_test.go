@@ -0,0 +1,32 @@
+package reflection
+
+import (
+	"strings"
+	"testing"
+)
+
+type printLogin struct {
+	User string
+	Pass string `print:"redact"`
+}
+
+func TestToStringRedact(t *testing.T) {
+	login := printLogin{User: "u", Pass: "secret"}
+	tests := []struct {
+		name string
+		got  string
+		show bool
+	}{
+		{"ToString", ToString(login), false},
+		{"indent", ToStringWithOptions(login, PrintOptions{Indent: "  "}), false},
+		{"max depth", ToStringWithOptions(login, PrintOptions{MaxDepth: 2}), false},
+		{"show redacted", ToStringWithOptions(login, PrintOptions{ShowRedacted: true}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(tt.got, "secret") != tt.show {
+				t.Errorf("got %q, secret shown = %v", tt.got, !tt.show)
+			}
+		})
+	}
+}
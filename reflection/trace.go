@@ -0,0 +1,97 @@
+package reflection
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// TraceKind is the kind of a TraceEvent
+type TraceKind int
+
+const (
+	// TraceVisited is sent before a member is mapped
+	TraceVisited TraceKind = iota
+	// TraceConverted is sent after a member is converted into another type
+	TraceConverted
+	// TraceSkipped is sent when a member is not mapped, TraceEvent.Message contains the reason
+	TraceSkipped
+	// TraceFailed is sent when a member fails to map, TraceEvent.Err contains the cause
+	TraceFailed
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceVisited:
+		return "visited"
+	case TraceConverted:
+		return "converted"
+	case TraceSkipped:
+		return "skipped"
+	case TraceFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// TraceEvent describes what happened to a member while mapping
+type TraceEvent struct {
+	Kind TraceKind
+	// Op is the operation being traced, example: mapper.Map()
+	Op string
+	// Path is the path of the member, example: Orders[3].Customer.Email
+	Path string
+	// From is the type of the source member, nil if the member is not found in the source
+	From reflect.Type
+	// To is the type of the destination member
+	To reflect.Type
+	// Message describes why a member is skipped
+	Message string
+	// Err is the cause of a failure
+	Err error
+}
+
+// Tracer receives events while values are mapped. Set Mapper.Tracer to diagnose a mapping, nothing is traced by default.
+type Tracer interface {
+	Trace(event TraceEvent)
+}
+
+// TracerFunc allows a function to be used as a Tracer
+type TracerFunc func(event TraceEvent)
+
+// Trace calls f(event)
+func (f TracerFunc) Trace(event TraceEvent) {
+	f(event)
+}
+
+// NewWriterTracer creates a Tracer that writes a line for each event to w
+func NewWriterTracer(w io.Writer) Tracer {
+	return TracerFunc(func(event TraceEvent) {
+		path := event.Path
+		if path == "" {
+			path = "(root)"
+		}
+		line := event.Op + " " + event.Kind.String() + " " + path + ": " + typeName(event.From) + " -> " + typeName(event.To)
+		if event.Message != "" {
+			line += " (" + event.Message + ")"
+		}
+		if event.Err != nil {
+			line += " (" + event.Err.Error() + ")"
+		}
+		fmt.Fprintln(w, line)
+	})
+}
+
+func typeName(typ reflect.Type) string {
+	if typ == nil {
+		return "<none>"
+	}
+	return typ.String()
+}
+
+// traceError sends a TraceFailed event for err if tracer is not nil
+func traceError(tracer Tracer, err *MappingError) {
+	if tracer != nil {
+		tracer.Trace(TraceEvent{Kind: TraceFailed, Op: err.Op, Path: err.Path, From: err.From, To: err.To, Err: err.Err})
+	}
+}
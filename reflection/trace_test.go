@@ -0,0 +1,38 @@
+package reflection
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type traceSource struct {
+	ID    int
+	Count int
+}
+
+type traceTarget struct {
+	ID    string
+	Count int64
+}
+
+func TestTraceConverted(t *testing.T) {
+	m := NewMapper()
+	m.Options.ConvertTypes = true
+	m.RegisterConverter(reflect.TypeOf(0), reflect.TypeOf(""), func(from interface{}) (interface{}, error) {
+		return strconv.Itoa(from.(int)), nil
+	})
+	converted := map[string]bool{}
+	m.Tracer = TracerFunc(func(event TraceEvent) {
+		if event.Kind == TraceConverted {
+			converted[event.Path] = true
+		}
+	})
+	var to traceTarget
+	if err := m.Map(&traceSource{ID: 1, Count: 2}, &to); err != nil {
+		t.Fatal(err)
+	}
+	if !converted["ID"] || !converted["Count"] {
+		t.Errorf("converted members = %v, want ID by the converter and Count by the built-in conversion", converted)
+	}
+}
@@ -0,0 +1,413 @@
+package reflection
+
+import (
+	"cmp"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidateTagName is the struct tag key read by Validate, example: `validate:"required,min=1,max=50"`
+const ValidateTagName = "validate"
+
+// ValidationError is a field that failed a validation rule
+type ValidationError struct {
+	// Path is the path of the field, example: Orders[3].Customer.Email
+	Path string
+	// Rule is the name of the failed rule, example: max
+	Rule string
+	// Param is the parameter of the failed rule, example: 50 for max=50
+	Param string
+	// Err describes why the value is invalid
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the rule
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors contains every field that failed validation
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns every failure, so errors.Is and errors.As check each of them
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// RuleContext is the field checked by a RuleFunc
+type RuleContext struct {
+	// Value is the value of the field, pointers are dereferenced. Invalid if the field is a nil pointer, which only the required rule receives.
+	Value reflect.Value
+	// Param is the parameter of the rule, example: 50 for max=50
+	Param string
+	// Parent is the struct containing the field
+	Parent reflect.Value
+}
+
+// Field gets another field of Parent for cross-field rules. name can be a dotted path and follows map tags like Map.
+func (ctx RuleContext) Field(name string) (reflect.Value, bool) {
+	val, exists := sourceField(ctx.Parent, name)
+	if !exists {
+		return reflect.Value{}, false
+	}
+	return indirect(val), true
+}
+
+// RuleFunc checks a field and returns an error describing why the value is invalid, or nil if it is valid
+type RuleFunc func(ctx RuleContext) error
+
+// Validator checks structs using the rules in their validate tags. Custom rules can be registered to a Validator.
+// Register rules before using the Validator from multiple goroutines.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFunc
+}
+
+// DefaultValidator is the Validator used by Validate
+var DefaultValidator = NewValidator()
+
+// NewValidator creates a Validator with only the built-in rules
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// RegisterRule registers a rule which can be used in validate tags as name or name=param. It replaces a built-in rule of the same name.
+func (v *Validator) RegisterRule(name string, rule RuleFunc) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.rules == nil {
+		v.rules = map[string]RuleFunc{}
+	}
+	v.rules[name] = rule
+}
+
+func (v *Validator) rule(name string) (RuleFunc, bool) {
+	v.mu.RLock()
+	rule, ok := v.rules[name]
+	v.mu.RUnlock()
+	if !ok {
+		rule, ok = builtinRules[name]
+	}
+	return rule, ok
+}
+
+// Validate checks every field of the struct obj, or a pointer to it, using DefaultValidator
+func Validate(obj interface{}) error {
+	return DefaultValidator.Validate(obj)
+}
+
+// Validate checks every field of the struct obj, or a pointer to it, against the rules in its validate tag.
+// Nested structs and the elements of slices, arrays and maps are validated recursively. The rules of a field are checked
+// in order and the first failure of each field is returned, all failures are returned together as ValidationErrors.
+func (v *Validator) Validate(obj interface{}) error {
+	val := indirect(reflect.ValueOf(obj))
+	if !val.IsValid() || val.Kind() != reflect.Struct {
+		return newMappingError("reflection.Validate()", reflect.TypeOf(obj), nil, "obj must be a struct or a pointer to a struct")
+	}
+	w := validation{validator: v, visiting: map[visitKey]bool{}}
+	w.walk(reflect.ValueOf(obj))
+	if len(w.invalid) > 0 {
+		return w.invalid
+	}
+	return nil
+}
+
+// validation holds the state of a single Validate call
+type validation struct {
+	mapContext
+	validator *Validator
+	invalid   ValidationErrors
+	// visiting contains the pointers currently being validated, to stop at cycles
+	visiting map[visitKey]bool
+}
+
+func (w *validation) walk(val reflect.Value) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		if val.Kind() == reflect.Ptr {
+			key := visitKey{val.Pointer(), val.Type()}
+			if w.visiting[key] {
+				return
+			}
+			w.visiting[key] = true
+			defer delete(w.visiting, key)
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		if !isOpaqueStruct(val.Type()) {
+			w.walkStruct(val)
+		}
+	case reflect.Slice, reflect.Array:
+		if isPlainType(val.Type().Elem()) {
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			w.pushIndex(i)
+			w.walk(val.Index(i))
+			w.pop()
+		}
+	case reflect.Map:
+		if isPlainType(val.Type().Elem()) {
+			return
+		}
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return lessValue(keys[i], keys[j])
+		})
+		for _, key := range keys {
+			w.pushKey(key)
+			w.walk(val.MapIndex(key))
+			w.pop()
+		}
+	}
+}
+
+func (w *validation) walkStruct(val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+		tag := fld.Tag.Get(ValidateTagName)
+		if fld.PkgPath != "" || tag == "-" {
+			continue
+		}
+		w.pushField(fld.Name)
+		if tag != "" {
+			w.check(val.Field(i), val, tag)
+		}
+		w.walk(val.Field(i))
+		w.pop()
+	}
+}
+
+// check runs the rules of tag against fldVal until one fails
+func (w *validation) check(fldVal reflect.Value, parent reflect.Value, tag string) {
+	val := indirect(fldVal)
+	for _, spec := range strings.Split(tag, ",") {
+		name, param := spec, ""
+		if idx := strings.Index(spec, "="); idx >= 0 {
+			name, param = spec[:idx], spec[idx+1:]
+		}
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case name == "omitempty":
+			if !val.IsValid() || isEmptyValue(val) {
+				return
+			}
+			continue
+		case !val.IsValid() && name != "required":
+			continue
+		}
+		rule, ok := w.validator.rule(name)
+		if !ok {
+			w.addError(name, param, errors.New("unknown rule "+name))
+			return
+		}
+		err := rule(RuleContext{Value: val, Param: param, Parent: parent})
+		if err != nil {
+			w.addError(name, param, err)
+			return
+		}
+	}
+}
+
+func (w *validation) addError(rule string, param string, err error) {
+	w.invalid = append(w.invalid, &ValidationError{Path: w.pathString(), Rule: rule, Param: param, Err: err})
+}
+
+// indirect dereferences pointers and interfaces, the returned value is invalid if a nil is found
+func indirect(val reflect.Value) reflect.Value {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+// isEmptyValue checks whether val is a zero value or an empty string, slice or map
+func isEmptyValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return val.Len() == 0
+	}
+	return val.IsZero()
+}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s.]+$`)
+
+var builtinRules = map[string]RuleFunc{
+	"required": ruleRequired,
+	"min":      ruleMin,
+	"max":      ruleMax,
+	"len":      ruleLen,
+	"email":    ruleEmail,
+	"oneof":    ruleOneOf,
+	"eqfield":  fieldRule("equal to", func(cmp int) bool { return cmp == 0 }),
+	"nefield":  fieldRule("different from", func(cmp int) bool { return cmp != 0 }),
+	"gtfield":  fieldRule("greater than", func(cmp int) bool { return cmp > 0 }),
+	"gtefield": fieldRule("greater than or equal to", func(cmp int) bool { return cmp >= 0 }),
+	"ltfield":  fieldRule("less than", func(cmp int) bool { return cmp < 0 }),
+	"ltefield": fieldRule("less than or equal to", func(cmp int) bool { return cmp <= 0 }),
+}
+
+func ruleRequired(ctx RuleContext) error {
+	if !ctx.Value.IsValid() || isEmptyValue(ctx.Value) {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+func ruleMin(ctx RuleContext) error {
+	return checkSize(ctx, "at least", func(size float64, limit float64) bool { return size >= limit })
+}
+
+func ruleMax(ctx RuleContext) error {
+	return checkSize(ctx, "at most", func(size float64, limit float64) bool { return size <= limit })
+}
+
+func ruleLen(ctx RuleContext) error {
+	return checkSize(ctx, "exactly", func(size float64, limit float64) bool { return size == limit })
+}
+
+// checkSize compares the length of a string, slice, array or map, or the value of a number with Param
+func checkSize(ctx RuleContext, desc string, test func(size float64, limit float64) bool) error {
+	limit, err := strconv.ParseFloat(ctx.Param, 64)
+	if err != nil {
+		return errors.New("invalid parameter \"" + ctx.Param + "\"")
+	}
+	val := ctx.Value
+	var size float64
+	subject := "must be "
+	switch kind := val.Kind(); {
+	case kind == reflect.String:
+		size = float64(utf8.RuneCountInString(val.String()))
+		subject = "length must be "
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		size = float64(val.Len())
+		subject = "length must be "
+	case isIntKind(kind):
+		size = float64(val.Int())
+	case isUintKind(kind):
+		size = float64(val.Uint())
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		size = val.Float()
+	default:
+		return errors.New("cannot check the size of " + val.Type().String())
+	}
+	if !test(size, limit) {
+		return errors.New(subject + desc + " " + ctx.Param)
+	}
+	return nil
+}
+
+func ruleEmail(ctx RuleContext) error {
+	if ctx.Value.Kind() != reflect.String {
+		return errors.New("cannot check " + ctx.Value.Type().String() + " as an email")
+	}
+	if !emailPattern.MatchString(ctx.Value.String()) {
+		return errors.New("must be a valid email")
+	}
+	return nil
+}
+
+// ruleOneOf checks whether a string or number is one of the values in Param separated by spaces
+func ruleOneOf(ctx RuleContext) error {
+	str, ok := formatValue(ctx.Value)
+	if ctx.Value.Kind() == reflect.String {
+		str, ok = ctx.Value.String(), true
+	}
+	if !ok {
+		return errors.New("cannot check " + ctx.Value.Type().String() + " with oneof")
+	}
+	for _, opt := range strings.Fields(ctx.Param) {
+		if opt == str {
+			return nil
+		}
+	}
+	return errors.New("must be one of " + ctx.Param)
+}
+
+// fieldRule creates a rule comparing the field with the field named in Param
+func fieldRule(desc string, test func(cmp int) bool) RuleFunc {
+	return func(ctx RuleContext) error {
+		other, exists := ctx.Field(ctx.Param)
+		if !exists {
+			return errors.New("field " + ctx.Param + " is not found")
+		}
+		if !other.IsValid() {
+			return nil
+		}
+		cmp, ok := compareValues(ctx.Value, other)
+		if !ok {
+			return errors.New("cannot compare " + ctx.Value.Type().String() + " with " + other.Type().String())
+		}
+		if !test(cmp) {
+			return errors.New("must be " + desc + " " + ctx.Param)
+		}
+		return nil
+	}
+}
+
+// compareValues compares two numbers, strings, bools or time.Time, returns false if they cannot be compared
+func compareValues(a reflect.Value, b reflect.Value) (int, bool) {
+	aKind := a.Kind()
+	bKind := b.Kind()
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	case isIntKind(aKind) && isIntKind(bKind):
+		return cmp.Compare(a.Int(), b.Int()), true
+	case isUintKind(aKind) && isUintKind(bKind):
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case isNumberKind(aKind) && isNumberKind(bKind):
+		return cmp.Compare(numberFloat(a), numberFloat(b)), true
+	case aKind == reflect.String && bKind == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case aKind == reflect.Bool && bKind == reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+func numberFloat(val reflect.Value) float64 {
+	switch kind := val.Kind(); {
+	case isIntKind(kind):
+		return float64(val.Int())
+	case isUintKind(kind):
+		return float64(val.Uint())
+	}
+	return val.Float()
+}
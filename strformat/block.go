@@ -0,0 +1,180 @@
+package strformat
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// scope is the data context of a template, %each% creates a scope for every item
+type scope struct {
+	data   interface{}
+	parent *scope
+	// vars are the loop variables like @index
+	vars map[string]interface{}
+}
+
+// lookup gets the value at path from the data of this scope, then from the outer scopes
+func (s *scope) lookup(path string) (interface{}, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if strings.HasPrefix(path, "@") {
+			if path == "@this" && sc.parent != nil {
+				return sc.data, true
+			}
+			if val, ok := sc.vars[path]; ok {
+				return val, true
+			}
+			continue
+		}
+		if val, ok := lookup(sc.data, path); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// openBlock is the root or an %if% or %each% block while it is parsed
+type openBlock struct {
+	keyword string
+	// pos is the offset of the block tag in the template
+	pos    int
+	expr   *exprNode
+	not    bool
+	body   []node
+	els    []node
+	inElse bool
+}
+
+func (b *openBlock) add(n node) {
+	if b.inElse {
+		b.els = append(b.els, n)
+	} else {
+		b.body = append(b.body, n)
+	}
+}
+
+func (b *openBlock) node() node {
+	if b.keyword == "each" {
+		return &eachNode{items: b.expr, body: b.body, els: b.els}
+	}
+	return &ifNode{cond: b.expr, not: b.not, then: b.body, els: b.els}
+}
+
+// parseBlockTag checks whether the content of a placeholder is a block tag like "if Discount", "each Items", "else" or "end"
+func parseBlockTag(content string) (keyword string, arg string, ok bool) {
+	if content != strings.TrimSpace(content) {
+		return "", "", false
+	}
+	switch content {
+	case "else", "end", "if", "each":
+		return content, "", true
+	}
+	for _, keyword := range []string{"if", "each"} {
+		if strings.HasPrefix(content, keyword+" ") {
+			return keyword, strings.TrimSpace(content[len(keyword)+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// ifNode is %if cond%then%else%els%end%
+type ifNode struct {
+	cond *exprNode
+	not  bool
+	then []node
+	els  []node
+}
+
+func (n *ifNode) exec(sf *StringFormatter, ctx *scope, sb *strings.Builder) {
+	val, ok := sf.eval(n.cond, ctx)
+	if (ok && truthy(val)) != n.not {
+		execNodes(n.then, sf, ctx, sb)
+	} else {
+		execNodes(n.els, sf, ctx, sb)
+	}
+}
+
+// eachNode is %each items%body%else%els%end%, els is written if there are no items
+type eachNode struct {
+	items *exprNode
+	body  []node
+	els   []node
+}
+
+func (n *eachNode) exec(sf *StringFormatter, ctx *scope, sb *strings.Builder) {
+	val, _ := sf.eval(n.items, ctx)
+	keys, items := iterate(val)
+	if len(items) == 0 {
+		execNodes(n.els, sf, ctx, sb)
+		return
+	}
+	for i, item := range items {
+		vars := map[string]interface{}{
+			"@index": i,
+			"@first": i == 0,
+			"@last":  i == len(items)-1,
+		}
+		if keys != nil {
+			vars["@key"] = keys[i]
+		}
+		execNodes(n.body, sf, &scope{data: item, parent: ctx, vars: vars}, sb)
+	}
+}
+
+func execNodes(nodes []node, sf *StringFormatter, ctx *scope, sb *strings.Builder) {
+	for _, n := range nodes {
+		n.exec(sf, ctx, sb)
+	}
+}
+
+// truthy checks whether the condition of %if% is met: nil, false, zero numbers, empty strings and empty collections are false
+func truthy(val interface{}) bool {
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return v.Len() > 0
+	case reflect.Struct:
+		return true
+	}
+	return !v.IsZero()
+}
+
+// iterate lists the items of a slice, an array or a map for %each%. Map items are sorted by key and their keys are returned.
+func iterate(val interface{}) (keys []interface{}, items []interface{}) {
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	case reflect.Map:
+		mapKeys := v.MapKeys()
+		sort.Slice(mapKeys, func(i, j int) bool {
+			return lessKey(mapKeys[i], mapKeys[j])
+		})
+		for _, k := range mapKeys {
+			keys = append(keys, k.Interface())
+			items = append(items, v.MapIndex(k).Interface())
+		}
+	}
+	return keys, items
+}
+
+// lessKey orders map keys, numbers by value and other keys by their text
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	}
+	return toString(a.Interface()) < toString(b.Interface())
+}
@@ -0,0 +1,68 @@
+package strformat
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Template is a template parsed by StringFormatter.Compile. It is safe to execute from multiple goroutines.
+type Template struct {
+	// sf is a copy of the StringFormatter which compiled the template, changes to the StringFormatter after Compile do not affect the template
+	sf    StringFormatter
+	nodes []node
+	// customKeys are the keys of CustomFormat in the order they are applied
+	customKeys []string
+}
+
+// Compile parses template like Format does. It returns a TemplateError if a block is not closed or not opened.
+// The CustomFormat, Functions, Filters and Locale of this StringFormatter are copied into the template.
+func (sf *StringFormatter) Compile(template string) (*Template, error) {
+	nodes, err := parseTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	tpl := &Template{sf: *sf, nodes: nodes}
+	tpl.sf.CustomFormat = map[string]func(string) string{}
+	for k, v := range sf.CustomFormat {
+		tpl.sf.CustomFormat[k] = v
+		tpl.customKeys = append(tpl.customKeys, k)
+	}
+	tpl.sf.Functions = map[string]TemplateFunc{}
+	for k, v := range sf.Functions {
+		tpl.sf.Functions[k] = v
+	}
+	tpl.sf.Filters = map[string]TemplateFilter{}
+	for k, v := range sf.Filters {
+		tpl.sf.Filters[k] = v
+	}
+	// longer keys first, so %date_long% is applied before %date%
+	sort.Slice(tpl.customKeys, func(i, j int) bool {
+		a, b := tpl.customKeys[i], tpl.customKeys[j]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return tpl, nil
+}
+
+// Execute writes the template formatted with data to w
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, t.ExecuteString(data))
+	return err
+}
+
+// ExecuteString returns the template formatted with data
+func (t *Template) ExecuteString(data interface{}) string {
+	var sb strings.Builder
+	execNodes(t.nodes, &t.sf, &scope{data: data}, &sb)
+	str := sb.String()
+
+	for _, k := range t.customKeys {
+		if strings.Contains(str, k) {
+			str = t.sf.CustomFormat[k](str)
+		}
+	}
+	return str
+}
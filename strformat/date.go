@@ -0,0 +1,174 @@
+package strformat
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatDate formats t using date directives. A directive is a run of the same letter, other characters are copied as is.
+// Use 'quotes' or a backslash to write letters literally.
+//
+//	yyyy  4-digit year         yy    2-digit year          y     year (same as yyyy)
+//	MMMM  month name           MMM   month abbreviation    MM, M 2-digit month       n     month without padding
+//	dddd  weekday name         ddd   weekday abbreviation  dd, d 2-digit day         j     day without padding
+//	HH, H 2-digit 24-hour      G     24-hour without padding
+//	hh, h 2-digit 12-hour      g     12-hour without padding
+//	ii, i, mm, m 2-digit minute                            ss, s 2-digit second
+//	fff   milliseconds         ff    centiseconds          f     deciseconds
+//	a     AM or PM
+//	Z     offset like +07:00   ZZ    offset like +0700     T     time zone name like WIB
+//	W     ISO week             WW    2-digit ISO week      o     ISO week year
+//	D     day of year          DDD   3-digit day of year
+func FormatDate(t time.Time, layout string) string {
+	return formatDate(t, layout, defaultLocale)
+}
+
+// formatDate formats t using the month names, weekday names and meridiem markers of locale
+func formatDate(t time.Time, layout string, locale *Locale) string {
+	var sb strings.Builder
+	for i := 0; i < len(layout); {
+		ch := layout[i]
+		if ch == '\\' && i+1 < len(layout) {
+			sb.WriteByte(layout[i+1])
+			i += 2
+			continue
+		}
+		if ch == '\'' {
+			end := strings.IndexByte(layout[i+1:], '\'')
+			if end < 0 {
+				sb.WriteString(layout[i+1:])
+				break
+			}
+			sb.WriteString(layout[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		count := 1
+		for i+count < len(layout) && layout[i+count] == ch {
+			count++
+		}
+		sb.WriteString(dateDirective(t, locale, ch, count, layout[i:i+count]))
+		i += count
+	}
+	return sb.String()
+}
+
+// dateDirective formats a run of count letters ch, unknown directives are returned as is
+func dateDirective(t time.Time, locale *Locale, ch byte, count int, raw string) string {
+	switch ch {
+	case 'y':
+		if count == 2 {
+			return pad(t.Year()%100, 2)
+		}
+		return pad(t.Year(), 4)
+	case 'M':
+		switch {
+		case count >= 4:
+			return locale.MonthNames[t.Month()-1]
+		case count == 3:
+			return locale.MonthAbbreviations[t.Month()-1]
+		}
+		return pad(int(t.Month()), 2)
+	case 'n':
+		return strconv.Itoa(int(t.Month()))
+	case 'd':
+		switch {
+		case count >= 4:
+			return locale.DayNames[t.Weekday()]
+		case count == 3:
+			return locale.DayAbbreviations[t.Weekday()]
+		}
+		return pad(t.Day(), 2)
+	case 'j':
+		return strconv.Itoa(t.Day())
+	case 'H':
+		return pad(t.Hour(), 2)
+	case 'G':
+		return strconv.Itoa(t.Hour())
+	case 'h':
+		return pad(hour12(t), 2)
+	case 'g':
+		return strconv.Itoa(hour12(t))
+	case 'i', 'm':
+		return pad(t.Minute(), 2)
+	case 's':
+		return pad(t.Second(), 2)
+	case 'f':
+		if count > 3 {
+			count = 3
+		}
+		return PadLeft(strconv.Itoa(t.Nanosecond()/1000000), "0", 3)[:count]
+	case 'a':
+		if t.Hour() < 12 {
+			return locale.AM
+		}
+		return locale.PM
+	case 'Z':
+		_, offset := t.Zone()
+		return formatOffset(offset, count == 1)
+	case 'T':
+		name, _ := t.Zone()
+		return name
+	case 'W':
+		_, week := t.ISOWeek()
+		if count >= 2 {
+			return pad(week, 2)
+		}
+		return strconv.Itoa(week)
+	case 'o':
+		year, _ := t.ISOWeek()
+		return strconv.Itoa(year)
+	case 'D':
+		if count >= 3 {
+			return pad(t.YearDay(), 3)
+		}
+		return strconv.Itoa(t.YearDay())
+	}
+	return raw
+}
+
+func hour12(t time.Time) int {
+	hour := t.Hour() % 12
+	if hour == 0 {
+		hour = 12
+	}
+	return hour
+}
+
+func pad(num int, length int) string {
+	return PadLeft(strconv.Itoa(num), "0", length)
+}
+
+// formatOffset formats a time zone offset in seconds like +07:00, or +0700 if colon is false
+func formatOffset(offset int, colon bool) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	sep := ""
+	if colon {
+		sep = ":"
+	}
+	return sign + pad(offset/3600, 2) + sep + pad(offset%3600/60, 2)
+}
+
+// loadZone loads a time zone by name like Asia/Jakarta, UTC and Local, or by offset like +07:00 and -0530
+func loadZone(name string) (*time.Location, bool) {
+	if name != "" && (name[0] == '+' || name[0] == '-') {
+		digits := strings.Replace(name[1:], ":", "", 1)
+		if len(digits) != 4 || strings.Trim(digits, CharsetNumber) != "" {
+			return nil, false
+		}
+		hours, _ := strconv.Atoi(digits[:2])
+		minutes, _ := strconv.Atoi(digits[2:])
+		offset := hours*3600 + minutes*60
+		if name[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), true
+	}
+	loc, err := time.LoadLocation(name)
+	return loc, err == nil
+}
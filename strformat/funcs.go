@@ -0,0 +1,124 @@
+package strformat
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinFunctions are the functions available in every template, StringFormatter.Functions can replace them
+var builtinFunctions = map[string]func(sf *StringFormatter, ctx *scope, args []string) (interface{}, bool){
+	"date":   funcDate,
+	"number": funcNumber,
+}
+
+// builtinFilters are the filters available in every template, StringFormatter.Filters can replace them
+var builtinFilters = map[string]func(sf *StringFormatter, value interface{}, args []string) interface{}{
+	"upper": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		return strings.ToUpper(toString(value))
+	},
+	"lower": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		return strings.ToLower(toString(value))
+	},
+	"capitalize": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		return Capitalize(toString(value))
+	},
+	"trim": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		return strings.TrimSpace(toString(value))
+	},
+	"padleft": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		length, padChar := padArgs(args)
+		return PadLeft(toString(value), padChar, length)
+	},
+	"padright": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		length, padChar := padArgs(args)
+		return PadRight(toString(value), padChar, length)
+	},
+	"date":   filterDate,
+	"number": filterNumber,
+	"default": func(sf *StringFormatter, value interface{}, args []string) interface{} {
+		if toString(value) == "" && len(args) > 0 {
+			return args[0]
+		}
+		return value
+	},
+}
+
+// padArgs reads the arguments of %x|padleft(length, char)%, char defaults to a space
+func padArgs(args []string) (int, string) {
+	length, padChar := 0, " "
+	if len(args) > 0 {
+		length, _ = strconv.Atoi(args[0])
+	}
+	if len(args) > 1 && args[1] != "" {
+		padChar = args[1]
+	}
+	return length, padChar
+}
+
+// locale gets the locale used by %date()%
+func (sf *StringFormatter) locale() *Locale {
+	if sf.Locale != nil {
+		return sf.Locale
+	}
+	return defaultLocale
+}
+
+// now gets the time used by %date()%
+func (sf *StringFormatter) now() time.Time {
+	if sf.UseCustomTime {
+		return sf.CustomTime
+	}
+	return time.Now()
+}
+
+// funcDate formats the current time using the Locale of sf. The optional second argument is the time zone and the
+// optional third argument is the name of a registered locale: %date(yyyy-MM-dd HH:mm, Asia/Jakarta)%, %date(long, , id)%
+func funcDate(sf *StringFormatter, ctx *scope, args []string) (interface{}, bool) {
+	return sf.formatDateArgs(sf.now(), args)
+}
+
+// filterDate formats a time.Time value like %date()%: %CreatedAt|date(dd MMM yyyy)%
+func filterDate(sf *StringFormatter, value interface{}, args []string) interface{} {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		t = *v
+	default:
+		return value
+	}
+	res, ok := sf.formatDateArgs(t, args)
+	if !ok {
+		return value
+	}
+	return res
+}
+
+// formatDateArgs formats t using the layout, optional time zone and optional locale name in args.
+// The layout can be the name of a pattern of the locale, an empty time zone keeps the time zone of t.
+func (sf *StringFormatter) formatDateArgs(t time.Time, args []string) (string, bool) {
+	if len(args) == 0 || len(args) > 3 {
+		return "", false
+	}
+	locale := sf.locale()
+	if len(args) == 3 {
+		l, ok := GetLocale(args[2])
+		if !ok {
+			return "", false
+		}
+		locale = l
+	}
+	if len(args) >= 2 && args[1] != "" {
+		loc, ok := loadZone(args[1])
+		if !ok {
+			return "", false
+		}
+		t = t.In(loc)
+	}
+	return locale.FormatDate(t, args[0]), true
+}
@@ -0,0 +1,136 @@
+package strformat
+
+import (
+	"sync"
+	"time"
+)
+
+// Locale contains the names, separators and patterns used to format dates and numbers
+type Locale struct {
+	// Name is the name the locale is registered with, example: en
+	Name string
+	// MonthNames are the names of the months from January
+	MonthNames [12]string
+	// MonthAbbreviations are the short names of the months from January
+	MonthAbbreviations [12]string
+	// DayNames are the names of the weekdays from Sunday
+	DayNames [7]string
+	// DayAbbreviations are the short names of the weekdays from Sunday
+	DayAbbreviations [7]string
+	// AM is the meridiem marker before noon
+	AM string
+	// PM is the meridiem marker after noon
+	PM string
+	// DatePatterns are named date formats which can be used as the format of %date()%, example: long -> MMMM j, yyyy
+	DatePatterns map[string]string
+	// ThousandSeparator separates the groups of 3 digits, example: , in 1,234
+	ThousandSeparator string
+	// DecimalSeparator separates the fraction, example: . in 1.5
+	DecimalSeparator string
+	// CurrencySymbol replaces ¤ in number patterns, example: $
+	CurrencySymbol string
+	// CompactSuffixes are written after compact numbers for thousands, millions and so on, the first one is for numbers below 1000
+	CompactSuffixes []string
+	// NumberPatterns are named number patterns which can be used as the pattern of %number()%, example: currency -> ¤#,##0.00
+	NumberPatterns map[string]string
+}
+
+// defaultLocale is used by FormatDate and by a StringFormatter without Locale
+var defaultLocale = LocaleCreateEnglish()
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]*Locale{
+		"en": LocaleCreateEnglish(),
+		"id": LocaleCreateIndonesian(),
+	}
+)
+
+// RegisterLocale registers a locale by its Name, so it can be found by GetLocale and used in %date(format, timezone, locale)%.
+// It replaces the locale registered with the same name.
+func RegisterLocale(locale *Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale.Name] = locale
+}
+
+// GetLocale gets a registered locale by name. English (en) and Indonesian (id) are registered by default.
+func GetLocale(name string) (*Locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	locale, ok := locales[name]
+	return locale, ok
+}
+
+// FormatDate formats t like FormatDate using the names of this locale. layout can also be the name of a pattern in
+// DatePatterns, example: long
+func (l *Locale) FormatDate(t time.Time, layout string) string {
+	if pattern, ok := l.DatePatterns[layout]; ok {
+		layout = pattern
+	}
+	return formatDate(t, layout, l)
+}
+
+// LocaleCreateEnglish creates locale struct for English language
+func LocaleCreateEnglish() *Locale {
+	locale := Locale{
+		Name:               "en",
+		MonthNames:         [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		MonthAbbreviations: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		DayNames:           [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		DayAbbreviations:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		AM:                 "AM",
+		PM:                 "PM",
+		DatePatterns: map[string]string{
+			"short":    "n/j/yy",
+			"medium":   "MMM j, yyyy",
+			"long":     "MMMM j, yyyy",
+			"full":     "dddd, MMMM j, yyyy",
+			"time":     "g:mm a",
+			"datetime": "MMM j, yyyy g:mm a",
+		},
+		ThousandSeparator: ",",
+		DecimalSeparator:  ".",
+		CurrencySymbol:    "$",
+		CompactSuffixes:   []string{"", "K", "M", "B", "T"},
+		NumberPatterns: map[string]string{
+			"decimal":  "#,##0.###",
+			"integer":  "#,##0",
+			"percent":  "#,##0%",
+			"currency": "¤#,##0.00",
+		},
+	}
+	return &locale
+}
+
+// LocaleCreateIndonesian creates locale struct for Indonesian language
+func LocaleCreateIndonesian() *Locale {
+	locale := Locale{
+		Name:               "id",
+		MonthNames:         [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		MonthAbbreviations: [12]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"},
+		DayNames:           [7]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+		DayAbbreviations:   [7]string{"Min", "Sen", "Sel", "Rab", "Kam", "Jum", "Sab"},
+		AM:                 "AM",
+		PM:                 "PM",
+		DatePatterns: map[string]string{
+			"short":    "dd/MM/yy",
+			"medium":   "j MMM yyyy",
+			"long":     "j MMMM yyyy",
+			"full":     "dddd, j MMMM yyyy",
+			"time":     "HH.mm",
+			"datetime": "j MMM yyyy HH.mm",
+		},
+		ThousandSeparator: ".",
+		DecimalSeparator:  ",",
+		CurrencySymbol:    "Rp",
+		CompactSuffixes:   []string{"", " rb", " jt", " M", " T"},
+		NumberPatterns: map[string]string{
+			"decimal":  "#,##0.###",
+			"integer":  "#,##0",
+			"percent":  "#,##0%",
+			"currency": "¤#,##0.00",
+		},
+	}
+	return &locale
+}
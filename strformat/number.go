@@ -0,0 +1,341 @@
+package strformat
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode is how a NumberFormat rounds the digits after its precision
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest digit, ties away from zero: 2.5 -> 3, -2.5 -> -3
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest digit, ties to the even digit: 2.5 -> 2, 3.5 -> 4
+	RoundHalfEven
+	// RoundHalfDown rounds to the nearest digit, ties toward zero: 2.5 -> 2, -2.5 -> -2
+	RoundHalfDown
+	// RoundUp rounds away from zero: 2.1 -> 3, -2.1 -> -3
+	RoundUp
+	// RoundDown rounds toward zero: 2.9 -> 2, -2.9 -> -2
+	RoundDown
+	// RoundCeiling rounds toward positive infinity: 2.1 -> 3, -2.9 -> -2
+	RoundCeiling
+	// RoundFloor rounds toward negative infinity: 2.9 -> 2, -2.1 -> -3
+	RoundFloor
+)
+
+// roundingModes are the names of the rounding modes in %number()%
+var roundingModes = map[string]RoundingMode{
+	"half-up":   RoundHalfUp,
+	"half-even": RoundHalfEven,
+	"half-down": RoundHalfDown,
+	"up":        RoundUp,
+	"down":      RoundDown,
+	"ceiling":   RoundCeiling,
+	"floor":     RoundFloor,
+}
+
+// NumberFormat describes how a number is written, it is usually created by ParseNumberFormat
+type NumberFormat struct {
+	// Prefix is written before the number, ¤ is replaced by the currency symbol of the locale
+	Prefix string
+	// Suffix is written after the number, ¤ is replaced by the currency symbol of the locale
+	Suffix string
+	// Grouping separates the integer digits in groups of 3
+	Grouping bool
+	// MinInteger is the minimum number of integer digits, padded with zeros
+	MinInteger int
+	// MinFraction is the minimum number of fraction digits, padded with zeros
+	MinFraction int
+	// MaxFraction is the number of fraction digits the number is rounded to
+	MaxFraction int
+	// Percent multiplies the number by 100
+	Percent bool
+	// Compact divides the number by thousands and writes the compact suffix of the locale, example: 1.2K
+	Compact bool
+	// Rounding is how the number is rounded to MaxFraction digits
+	Rounding RoundingMode
+}
+
+// ParseNumberFormat parses a pattern like #,##0.00. In the pattern 0 is a digit, # is an optional digit, , enables
+// grouping and . starts the fraction, whatever the separators of the locale are. Text around the digits is written as
+// is, ¤ is the currency symbol and % multiplies the number by 100, examples: ¤#,##0.00, #,##0.00 €, 0.#%
+func ParseNumberFormat(pattern string) (NumberFormat, error) {
+	var nf NumberFormat
+	start := strings.IndexAny(pattern, "#0")
+	if start < 0 {
+		return nf, errors.New("number pattern " + strconv.Quote(pattern) + " has no digit")
+	}
+	for start > 0 && strings.IndexByte("#0,.", pattern[start-1]) >= 0 {
+		start--
+	}
+	end := start
+	for end < len(pattern) && strings.IndexByte("#0,.", pattern[end]) >= 0 {
+		end++
+	}
+	nf.Prefix, nf.Suffix = pattern[:start], pattern[end:]
+	intPart, fracPart, _ := strings.Cut(pattern[start:end], ".")
+	if strings.ContainsAny(fracPart, ".,") {
+		return nf, errors.New("number pattern " + strconv.Quote(pattern) + " has an invalid fraction")
+	}
+	nf.Grouping = strings.Contains(intPart, ",")
+	nf.MinInteger = strings.Count(intPart, "0")
+	nf.MinFraction = strings.Count(fracPart, "0")
+	nf.MaxFraction = len(fracPart)
+	nf.Percent = strings.Contains(nf.Prefix+nf.Suffix, "%")
+	return nf, nil
+}
+
+// Format formats value using the separators, currency symbol and compact suffixes of locale, English if locale is nil
+func (nf NumberFormat) Format(value float64, locale *Locale) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return nf.format(strconv.FormatFloat(value, 'f', -1, 64), locale)
+}
+
+// format formats a plain decimal like -1234.5, the digits are rounded as text so no precision is lost
+func (nf NumberFormat) format(decimal string, locale *Locale) string {
+	if locale == nil {
+		locale = defaultLocale
+	}
+	neg := strings.HasPrefix(decimal, "-")
+	intPart, fracPart, _ := strings.Cut(strings.TrimLeft(decimal, "+-"), ".")
+	if nf.Percent {
+		intPart, fracPart = shiftDecimal(intPart, fracPart, 2)
+	}
+	group := 0
+	if nf.Compact {
+		digits := len(strings.TrimLeft(intPart, "0"))
+		for group+1 < len(locale.CompactSuffixes) && digits > 3*(group+1) {
+			group++
+		}
+	}
+	var i, f string
+	for {
+		i, f = shiftDecimal(intPart, fracPart, -3*group)
+		i, f = nf.round(i, f, neg)
+		// 999.95K is rounded to 1000.0K, write 1.0M instead
+		if !nf.Compact || len(i) <= 3 || group+1 >= len(locale.CompactSuffixes) {
+			break
+		}
+		group++
+	}
+	for len(f) > nf.MinFraction && f[len(f)-1] == '0' {
+		f = f[:len(f)-1]
+	}
+	f += strings.Repeat("0", max(0, nf.MinFraction-len(f)))
+	i = strings.Repeat("0", max(0, nf.MinInteger-len(i))) + i
+	if i == "" && f == "" {
+		i = "0"
+	}
+	if strings.Trim(i+f, "0") == "" {
+		neg = false
+	}
+
+	var sb strings.Builder
+	if neg {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(strings.ReplaceAll(nf.Prefix, "¤", locale.CurrencySymbol))
+	for n, ch := range i {
+		if nf.Grouping && n > 0 && (len(i)-n)%3 == 0 {
+			sb.WriteString(locale.ThousandSeparator)
+		}
+		sb.WriteRune(ch)
+	}
+	if f != "" {
+		sb.WriteString(locale.DecimalSeparator)
+		sb.WriteString(f)
+	}
+	if nf.Compact {
+		sb.WriteString(locale.CompactSuffixes[group])
+	}
+	sb.WriteString(strings.ReplaceAll(nf.Suffix, "¤", locale.CurrencySymbol))
+	return sb.String()
+}
+
+// round rounds the digits intPart.fracPart to MaxFraction digits
+func (nf NumberFormat) round(intPart string, fracPart string, neg bool) (string, string) {
+	p := nf.MaxFraction
+	if len(fracPart) <= p {
+		return intPart, fracPart
+	}
+	digits, rest := intPart+fracPart[:p], fracPart[p:]
+	up := false
+	switch nf.Rounding {
+	case RoundUp:
+		up = true
+	case RoundDown:
+	case RoundCeiling:
+		up = !neg
+	case RoundFloor:
+		up = neg
+	default:
+		tie := rest[0] == '5' && strings.Trim(rest[1:], "0") == ""
+		switch {
+		case rest[0] > '5' || rest[0] == '5' && !tie:
+			up = true
+		case tie && nf.Rounding == RoundHalfUp:
+			up = true
+		case tie && nf.Rounding == RoundHalfEven:
+			up = digits != "" && (digits[len(digits)-1]-'0')%2 == 1
+		}
+	}
+	if up {
+		digits = incrementDigits(digits)
+	}
+	cut := len(digits) - p
+	return strings.TrimLeft(digits[:cut], "0"), digits[cut:]
+}
+
+// incrementDigits adds 1 to a string of digits: 199 -> 200
+func incrementDigits(digits string) string {
+	b := []byte(digits)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = '0'
+	}
+	return "1" + string(b)
+}
+
+// shiftDecimal multiplies intPart.fracPart by 10^n, a negative n divides it
+func shiftDecimal(intPart string, fracPart string, n int) (string, string) {
+	if n > 0 {
+		fracPart += strings.Repeat("0", max(0, n-len(fracPart)))
+		intPart, fracPart = intPart+fracPart[:n], fracPart[n:]
+	} else if n < 0 {
+		intPart = strings.Repeat("0", max(0, -n-len(intPart))) + intPart
+		cut := len(intPart) + n
+		intPart, fracPart = intPart[:cut], intPart[cut:]+fracPart
+	}
+	return strings.TrimLeft(intPart, "0"), strings.TrimRight(fracPart, "0")
+}
+
+// FormatNumber formats value using English separators, pattern is a pattern like #,##0.00 or the name of a pattern of
+// the English locale like currency. The value is written with strconv if the pattern is invalid.
+func FormatNumber(value float64, pattern string) string {
+	return defaultLocale.FormatNumber(value, pattern)
+}
+
+// ParseNumberFormat parses a pattern like ParseNumberFormat, pattern can also be the name of a pattern in NumberPatterns
+func (l *Locale) ParseNumberFormat(pattern string) (NumberFormat, error) {
+	if named, ok := l.NumberPatterns[pattern]; ok {
+		pattern = named
+	}
+	return ParseNumberFormat(pattern)
+}
+
+// FormatNumber formats value like FormatNumber using the separators, currency symbol and patterns of this locale
+func (l *Locale) FormatNumber(value float64, pattern string) string {
+	nf, err := l.ParseNumberFormat(pattern)
+	if err != nil {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return nf.Format(value, l)
+}
+
+// funcNumber formats a number from data or a literal number: %number(Price, currency)%, %number(Total, "#,##0.00", id)%.
+// The arguments after the value are the pattern, a rounding mode, percent, compact and the name of a locale, in any order.
+func funcNumber(sf *StringFormatter, ctx *scope, args []string) (interface{}, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	val, ok := ctx.lookup(args[0])
+	if !ok {
+		val = args[0]
+	}
+	return sf.formatNumberArgs(val, args[1:])
+}
+
+// filterNumber formats a number value like %number()%: %Price|number(currency)%
+func filterNumber(sf *StringFormatter, value interface{}, args []string) interface{} {
+	res, ok := sf.formatNumberArgs(value, args)
+	if !ok {
+		return value
+	}
+	return res
+}
+
+// formatNumberArgs formats value using the pattern and options in args, the pattern defaults to decimal
+func (sf *StringFormatter) formatNumberArgs(value interface{}, args []string) (string, bool) {
+	decimal, ok := toDecimal(value)
+	if !ok {
+		return "", false
+	}
+	locale := sf.locale()
+	pattern := ""
+	var options []string
+	for _, arg := range args {
+		_, isRounding := roundingModes[arg]
+		l, isLocale := GetLocale(arg)
+		switch {
+		case isRounding || arg == "percent" || arg == "compact":
+			options = append(options, arg)
+		case isLocale:
+			locale = l
+		default:
+			pattern = arg
+		}
+	}
+	if pattern == "" {
+		pattern = "decimal"
+	}
+	nf, err := locale.ParseNumberFormat(pattern)
+	if err != nil {
+		return "", false
+	}
+	for _, opt := range options {
+		switch opt {
+		case "percent":
+			nf.Percent = true
+			if !strings.Contains(nf.Prefix+nf.Suffix, "%") {
+				nf.Suffix += "%"
+			}
+		case "compact":
+			nf.Compact = true
+		default:
+			nf.Rounding = roundingModes[opt]
+		}
+	}
+	return nf.format(decimal, locale), true
+}
+
+// toDecimal writes a number as a plain decimal like -1234.5, a string is kept as is if it is already a plain decimal
+func toDecimal(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		str := strings.TrimSpace(v)
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return "", false
+		}
+		if strings.Trim(str, "+-.0123456789") == "" {
+			return str, true
+		}
+		value = f
+	case float32:
+		value = float64(v)
+	}
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	}
+	return "", false
+}
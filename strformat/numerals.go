@@ -1,7 +1,10 @@
 package strformat
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -29,7 +32,7 @@ type Numeral struct {
 	GroupNames map[int]string
 	// PointConversion is the name of the point
 	PointConversion string
-	// Correction will correct substring into mapped string, example: two ty -> twenty
+	// Correction will correct substring into mapped string, example: two ty -> twenty. A whole group with its group name is also corrected, example: satu ribu -> seribu
 	Correction map[string]string
 	// CurrencyName is the name of the currency
 	CurrencyName string
@@ -39,54 +42,148 @@ type Numeral struct {
 	CurrencyPointConversion string
 	// CurrencyPointLength is the length of the currency decimal point
 	CurrencyPointLength int
+	// NegativeConversion is the word before negative numbers, example: minus
+	NegativeConversion string
+	// OrdinalLiteral will convert number to ordinal text if the whole value is found, example: 1 -> pertama
+	OrdinalLiteral map[int]string
+	// OrdinalConversion will convert the last word of the number text to its ordinal, example: two -> second
+	OrdinalConversion map[string]string
+	// OrdinalSuffix is appended to the last word if it is not found in OrdinalConversion, example: hundred -> hundredth
+	OrdinalSuffix string
+	// OrdinalPrefix is prepended to the number text, example: dua -> kedua
+	OrdinalPrefix string
+	// OrdinalCorrection will correct substring of the ordinal text into mapped string, example: ty second -> ty-second
+	OrdinalCorrection map[string]string
+	// OrdinalNumberPrefix is prepended to the digits of an ordinal, example: ke- -> ke-2
+	OrdinalNumberPrefix string
+	// OrdinalNumberSuffixes are appended to the digits of an ordinal, taken from the value mod 100, then mod 10, then key 0. example: 2 -> 2nd
+	OrdinalNumberSuffixes map[int]string
 }
 
+// ErrNumeralTooLarge is returned when a number has more digit groups than the GroupNames of a Numeral
+var ErrNumeralTooLarge = errors.New("number is too large for the group names")
+
+// ConvertCurrency converts value to currency text rounded to CurrencyPointLength, example: 1.5 -> one and fifty cents.
+// Returns an empty string if value cannot be converted, use ConvertCurrencyDecimal to get the error.
 func (n *Numeral) ConvertCurrency(value float64) string {
-	strVal := strconv.FormatFloat(value, 'f', n.CurrencyPointLength, 64)
-	spl := strings.Split(strVal, ".")
-	digits := spl[0]
-	points := ""
-	if len(spl) == 2 {
-		points = spl[1]
+	res, err := n.ConvertCurrencyDecimal(strconv.FormatFloat(value, 'f', n.CurrencyPointLength, 64))
+	if err != nil {
+		return ""
 	}
+	return res
+}
 
-	digitInt, e := strconv.Atoi(digits)
-	res := ""
-	if e == nil {
-		res = n.Convert(float64(digitInt), 0)
+// ConvertCurrencyDecimal converts a decimal string like -1234.56 to currency text without float rounding,
+// the decimal points after CurrencyPointLength are dropped
+func (n *Numeral) ConvertCurrencyDecimal(value string) (string, error) {
+	neg, digits, points, err := parseDecimal(value)
+	if err != nil {
+		return "", err
+	}
+	res, err := n.convertDigits(digits)
+	if err != nil {
+		return "", err
 	}
-	res = strings.Trim(res, " \t")
 
-	if points != "" {
-		points = points[0:int(math.Min(float64(len(points)), float64(n.CurrencyPointLength)))]
-		for len(points) < n.CurrencyPointLength {
-			points += "0"
+	if len(points) > n.CurrencyPointLength {
+		points = points[:n.CurrencyPointLength]
+	}
+	points += strings.Repeat("0", n.CurrencyPointLength-len(points))
+	if strings.Trim(points, "0") != "" {
+		ptWord, err := n.convertDigits(strings.TrimLeft(points, "0"))
+		if err != nil {
+			return "", err
 		}
-		ptInt, e := strconv.Atoi(points)
-		if e == nil && ptInt != 0 {
-			res += " " + n.CurrencyPointConversion + " " + n.Convert(float64(ptInt), 0)
-			if n.CurrencyPointName != "" {
-				res += " " + n.CurrencyPointName
-			}
+		res += " " + n.CurrencyPointConversion + " " + ptWord
+		if n.CurrencyPointName != "" {
+			res += " " + n.CurrencyPointName
 		}
 	}
 
 	if n.CurrencyName != "" {
 		res += " " + n.CurrencyName
 	}
-
-	return res + n.CurrencyName
+	if neg && strings.Trim(digits+points, "0") != "" {
+		res = n.NegativeConversion + " " + res
+	}
+	return res, nil
 }
 
+// Convert converts value with prec decimal points to text, example: 12.5 -> twelve point five.
+// Returns an empty string if value cannot be converted, use ConvertDecimal to get the error.
 func (n *Numeral) Convert(value float64, prec int) string {
-	strVal := strconv.FormatFloat(value, 'f', prec, 64)
-	spl := strings.Split(strVal, ".")
-	digits := spl[0]
-	points := ""
-	if len(spl) == 2 {
-		points = spl[1]
+	res, err := n.ConvertDecimal(strconv.FormatFloat(value, 'f', prec, 64))
+	if err != nil {
+		return ""
+	}
+	return res
+}
+
+// ConvertInt64 converts value to text, example: -12 -> minus twelve
+func (n *Numeral) ConvertInt64(value int64) (string, error) {
+	return n.ConvertDecimal(strconv.FormatInt(value, 10))
+}
+
+// ConvertBig converts value to text, returns ErrNumeralTooLarge if value has more digit groups than GroupNames
+func (n *Numeral) ConvertBig(value *big.Int) (string, error) {
+	if value == nil {
+		return "", errors.New("value is nil")
+	}
+	return n.ConvertDecimal(value.String())
+}
+
+// ConvertDecimal converts a decimal string like -98237221597389.37182 to text without float rounding.
+// Every decimal point is converted digit by digit. Returns ErrNumeralTooLarge if value has more digit groups than GroupNames.
+func (n *Numeral) ConvertDecimal(value string) (string, error) {
+	neg, digits, points, err := parseDecimal(value)
+	if err != nil {
+		return "", err
+	}
+	res, err := n.convertDigits(digits)
+	if err != nil {
+		return "", err
+	}
+
+	if points != "" {
+		ptWord := ""
+		for i := 0; i < len(points); i++ {
+			if points[i] == '0' {
+				ptWord += " " + n.ZeroConversion
+			} else {
+				ptWord += " " + n.Conversion[int(points[i]-'0')]
+			}
+		}
+
+		ptWord = strings.Trim(ptWord, " ")
+		if ptWord != "" {
+			res += " " + n.PointConversion + " " + ptWord
+		}
 	}
+	if neg && strings.Trim(digits+points, "0") != "" {
+		res = n.NegativeConversion + " " + res
+	}
+	return res, nil
+}
 
+// parseDecimal splits a decimal string like -1234.56 into its sign, integer digits without leading zeros and decimal points
+func parseDecimal(value string) (neg bool, digits string, points string, err error) {
+	str := strings.TrimSpace(value)
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+	digits, points, _ = strings.Cut(str, ".")
+	if digits+points == "" || strings.Trim(digits+points, CharsetNumber) != "" {
+		return false, "", "", errors.New("invalid decimal " + strconv.Quote(value))
+	}
+	return neg, strings.TrimLeft(digits, "0"), points, nil
+}
+
+// convertDigits converts the integer digits to text, the digits must not have leading zeros
+func (n *Numeral) convertDigits(digits string) (string, error) {
+	if digits == "" {
+		return n.ZeroConversion, nil
+	}
 	res := ""
 	group := ""
 	gIdx := 0
@@ -95,8 +192,15 @@ func (n *Numeral) Convert(value float64, prec int) string {
 		if len(group) == n.SplitDigit || i == 0 {
 			groupStr := n.groupConvert(group)
 			groupName, ok := n.GroupNames[gIdx]
+			if !ok && gIdx > 0 && groupStr != "" {
+				return "", fmt.Errorf("%s has %d digits: %w", digits, len(digits), ErrNumeralTooLarge)
+			}
 			if ok && groupName != "" && groupStr != "" {
 				groupStr += " " + groupName
+				// a whole group with its name can be corrected, example: satu ribu -> seribu but not dua puluh satu ribu
+				if cor, ok := n.Correction[groupStr]; ok {
+					groupStr = cor
+				}
 			}
 			if groupStr != "" {
 				res = groupStr + " " + res
@@ -105,27 +209,53 @@ func (n *Numeral) Convert(value float64, prec int) string {
 			gIdx++
 		}
 	}
-	res = strings.Trim(res, " \t")
+	return strings.Trim(res, " \t"), nil
+}
 
-	if points != "" {
-		ptWord := ""
-		for i := 0; i < len(points); i++ {
-			pt, e := strconv.Atoi(points[i : i+1])
-			if e == nil {
-				ptWord += " " + n.Conversion[pt]
-			} else {
-				ptWord += " " + n.ZeroConversion
-			}
-		}
+// ConvertOrdinal converts value to ordinal text, example: 22 -> twenty-second, 100 -> keseratus.
+// Returns an empty string if value is negative or has more digit groups than GroupNames.
+func (n *Numeral) ConvertOrdinal(value int) string {
+	if value < 0 {
+		return ""
+	}
+	if lit, ok := n.OrdinalLiteral[value]; ok {
+		return lit
+	}
+	res, err := n.ConvertInt64(int64(value))
+	if err != nil {
+		return ""
+	}
+	words := strings.Split(res, " ")
+	last := words[len(words)-1]
+	if ord, ok := n.OrdinalConversion[last]; ok {
+		last = ord
+	} else {
+		last += n.OrdinalSuffix
+	}
+	words[len(words)-1] = last
+	res = n.OrdinalPrefix + strings.Join(words, " ")
 
-		ptWord = strings.Trim(ptWord, " ")
-		if ptWord != "" {
-			res += " " + n.PointConversion + " " + ptWord
-		}
+	for key, cor := range n.OrdinalCorrection {
+		res = strings.Replace(res, key, cor, -1)
 	}
 	return res
 }
 
+// FormatOrdinal writes value as an ordinal in digits, example: 1 -> 1st, 2 -> ke-2. Returns an empty string if value is negative.
+func (n *Numeral) FormatOrdinal(value int) string {
+	if value < 0 {
+		return ""
+	}
+	suffix, ok := n.OrdinalNumberSuffixes[value%100]
+	if !ok {
+		suffix, ok = n.OrdinalNumberSuffixes[value%10]
+	}
+	if !ok {
+		suffix = n.OrdinalNumberSuffixes[0]
+	}
+	return n.OrdinalNumberPrefix + strconv.Itoa(value) + suffix
+}
+
 func (n *Numeral) groupConvert(group string) string {
 	res := ""
 	grVal, e := strconv.Atoi(group)
@@ -215,6 +345,12 @@ func NumeralCreateIndonesian() *Numeral {
 		CurrencyPointConversion: "dan",
 		CurrencyPointName:       "sen",
 		CurrencyPointLength:     2,
+		NegativeConversion:      "negatif",
+		OrdinalLiteral: map[int]string{
+			1: "pertama",
+		},
+		OrdinalPrefix:       "ke",
+		OrdinalNumberPrefix: "ke-",
 	}
 	return &num
 }
@@ -261,7 +397,7 @@ func NumeralCreateEnglish() *Numeral {
 		Correction: map[string]string{
 			"two ty":   "twenty",
 			"three ty": "thirty",
-			"four ty":  "fourty",
+			"four ty":  "forty",
 			"five ty":  "fifty",
 			"six ty":   "sixty",
 			"seven ty": "seventy",
@@ -273,6 +409,45 @@ func NumeralCreateEnglish() *Numeral {
 		CurrencyPointConversion: "and",
 		CurrencyPointName:       "cents",
 		CurrencyPointLength:     2,
+		NegativeConversion:      "minus",
+		OrdinalConversion: map[string]string{
+			"one":     "first",
+			"two":     "second",
+			"three":   "third",
+			"five":    "fifth",
+			"eight":   "eighth",
+			"nine":    "ninth",
+			"twelve":  "twelfth",
+			"twenty":  "twentieth",
+			"thirty":  "thirtieth",
+			"forty":   "fortieth",
+			"fifty":   "fiftieth",
+			"sixty":   "sixtieth",
+			"seventy": "seventieth",
+			"eighty":  "eightieth",
+			"ninety":  "ninetieth",
+		},
+		OrdinalSuffix: "th",
+		OrdinalCorrection: map[string]string{
+			"ty first":   "ty-first",
+			"ty second":  "ty-second",
+			"ty third":   "ty-third",
+			"ty fourth":  "ty-fourth",
+			"ty fifth":   "ty-fifth",
+			"ty sixth":   "ty-sixth",
+			"ty seventh": "ty-seventh",
+			"ty eighth":  "ty-eighth",
+			"ty ninth":   "ty-ninth",
+		},
+		OrdinalNumberSuffixes: map[int]string{
+			0:  "th",
+			1:  "st",
+			2:  "nd",
+			3:  "rd",
+			11: "th",
+			12: "th",
+			13: "th",
+		},
 	}
 	return &num
 }
@@ -0,0 +1,28 @@
+package strformat
+
+import "testing"
+
+func TestOrdinal(t *testing.T) {
+	en, id := NumeralCreateEnglish(), NumeralCreateIndonesian()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"english words", en.ConvertOrdinal(22), "twenty-second"},
+		{"english digits", en.FormatOrdinal(3), "3rd"},
+		{"indonesian words", id.ConvertOrdinal(1000), "keseribu"},
+		{"indonesian digits", id.FormatOrdinal(3), "ke-3"},
+		{"english negative words", en.ConvertOrdinal(-3), ""},
+		{"english negative digits", en.FormatOrdinal(-3), ""},
+		{"indonesian negative words", id.ConvertOrdinal(-3), ""},
+		{"indonesian negative digits", id.FormatOrdinal(-3), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -9,67 +9,56 @@ package strformat
 */
 
 import (
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // StringFormatter is used to format a string template, it comes with custom format.
-// Note: Call Init() before adding CustomFormat or it will panic
+// Note: Call Init() before adding CustomFormat, Functions or Filters or it will panic
 type StringFormatter struct {
 	CustomFormat  map[string]func(string) string
 	UseCustomTime bool
 	CustomTime    time.Time
+	// Functions resolves placeholders with arguments like %name(arg1,arg2)%, they replace built-in functions of the same name
+	Functions map[string]TemplateFunc
+	// Filters transforms placeholder values like %price|upper%, they replace built-in filters of the same name
+	Filters map[string]TemplateFilter
+	// Locale supplies the month names, weekday names, meridiem markers and date patterns of %date()%, defaults to English
+	Locale *Locale
 }
 
-// FormatString formats a specified string using Format
+// FormatString formats a specified string using Format without data
 func (sf *StringFormatter) FormatString(str string) string {
-	var regex, err = regexp.Compile("(%date\\([yMdHhmsa]+\\)%)")
-	var cts = time.Now()
-	if sf.UseCustomTime {
-		cts = sf.CustomTime
-	}
-	if err == nil {
-		str = regex.ReplaceAllStringFunc(str, func(a string) string {
-			a = strings.TrimPrefix(a, "%date(")
-			a = strings.TrimSuffix(a, ")%")
-
-			var HH = cts.Hour()
-			var hh = cts.Hour() % 12
-			var aa = "AM"
-			if HH > 12 {
-				aa = "PM"
-			}
-			if hh == 0 {
-				hh = 12
-			}
-
-			a = strings.Replace(a, "y", strconv.Itoa(cts.Year()), 1)
-			a = strings.Replace(a, "M", PadLeft(strconv.Itoa(int(cts.Month())), "0", 2), 1)
-			a = strings.Replace(a, "d", PadLeft(strconv.Itoa(cts.Day()), "0", 2), 1)
-			a = strings.Replace(a, "H", PadLeft(strconv.Itoa(HH), "0", 2), 1)
-			a = strings.Replace(a, "h", PadLeft(strconv.Itoa(hh), "0", 2), 1)
-			a = strings.Replace(a, "i", PadLeft(strconv.Itoa(cts.Minute()), "0", 2), 1)
-			a = strings.Replace(a, "s", PadLeft(strconv.Itoa(cts.Second()), "0", 2), 1)
-			a = strings.Replace(a, "a", aa, 1)
-			return a
-		})
+	res, err := sf.Format(str, nil)
+	if err != nil {
+		return str
 	}
+	return res
+}
 
-	if sf.CustomFormat != nil {
-		for k, v := range sf.CustomFormat {
-			if strings.Contains(str, k) {
-				str = v(str)
-			}
-		}
+// Format formats a template. Placeholders like %name% are replaced by the member of data at name, which can be a dotted
+// path like %Customer.Name% or %Items[0].Price%. Placeholders can call a function with arguments like %date(yMd)% and
+// be piped through filters like %name|trim|upper%. Use %% for a literal %. Placeholders which cannot be resolved are
+// kept as is, then CustomFormat is applied to the result, longer keys first.
+//
+// Blocks render their content conditionally or once per item: %if Discount%...%else%...%end%, %if not Paid%...%end%
+// and %each Items%...%else%no items%end%. Inside %each% names are looked up in the item first, then in the outer data,
+// and @index, @key, @first, @last and @this describe the item. A block which is not closed returns a TemplateError.
+//
+// Use Compile to parse a template once and execute it many times.
+func (sf *StringFormatter) Format(template string, data interface{}) (string, error) {
+	tpl, err := sf.Compile(template)
+	if err != nil {
+		return "", err
 	}
-	return str
+	return tpl.ExecuteString(data), nil
 }
 
 // Init initializes this StringFormatter
 func (sf *StringFormatter) Init() {
 	sf.CustomFormat = map[string]func(string) string{}
+	sf.Functions = map[string]TemplateFunc{}
+	sf.Filters = map[string]TemplateFilter{}
 }
 
 // PadLeft pads the left of a string with specified char so that the string will have a length of totalLength
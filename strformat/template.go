@@ -0,0 +1,325 @@
+package strformat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zecchan/zgolib/reflection"
+)
+
+// TemplateFunc resolves a placeholder with arguments like %name(arg1,arg2)%. data is the data passed to Format, or the
+// current item inside %each%. Returns false to leave the placeholder as is.
+type TemplateFunc func(data interface{}, args []string) (interface{}, bool)
+
+// TemplateFilter transforms the value of a placeholder like %price|upper%. args are the arguments of the filter like %name|padleft(10)%
+type TemplateFilter func(value interface{}, args []string) interface{}
+
+// TemplateError is a syntax error in a template, like an %if% without %end%
+type TemplateError struct {
+	// Line is the line of the error, starting from 1
+	Line int
+	// Column is the column of the error in characters, starting from 1
+	Column int
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// newTemplateError creates a TemplateError at byte offset pos of src
+func newTemplateError(src string, pos int, msg string) *TemplateError {
+	lineStart := strings.LastIndexByte(src[:pos], '\n') + 1
+	return &TemplateError{
+		Line:   strings.Count(src[:pos], "\n") + 1,
+		Column: utf8.RuneCountInString(src[lineStart:pos]) + 1,
+		Err:    errors.New(msg),
+	}
+}
+
+// node is a parsed part of a template
+type node interface {
+	exec(sf *StringFormatter, ctx *scope, sb *strings.Builder)
+}
+
+// textNode is a literal text
+type textNode string
+
+func (n textNode) exec(sf *StringFormatter, ctx *scope, sb *strings.Builder) {
+	sb.WriteString(string(n))
+}
+
+// call is a name with optional arguments, example: date(yyyy-MM-dd)
+type call struct {
+	name    string
+	args    []string
+	hasArgs bool
+}
+
+// exprNode is a placeholder like %name(args)|filter|filter(args)%
+type exprNode struct {
+	// raw is the placeholder as written, it is written as is if the placeholder cannot be resolved
+	raw     string
+	value   call
+	filters []call
+}
+
+func (n *exprNode) exec(sf *StringFormatter, ctx *scope, sb *strings.Builder) {
+	val, ok := sf.eval(n, ctx)
+	if !ok {
+		sb.WriteString(n.raw)
+		return
+	}
+	sb.WriteString(toString(val))
+}
+
+// eval resolves the value of expr and applies its filters, returns false if the value or a filter cannot be resolved
+func (sf *StringFormatter) eval(expr *exprNode, ctx *scope) (interface{}, bool) {
+	val, ok := sf.resolve(expr.value, ctx)
+	if !ok {
+		return nil, false
+	}
+	for _, f := range expr.filters {
+		filter, ok := sf.filter(f.name)
+		if !ok {
+			return nil, false
+		}
+		val = filter(val, f.args)
+	}
+	return val, true
+}
+
+// parseTemplate splits src into text, placeholders and blocks. %% is a literal %, a % which does not start a valid
+// placeholder is kept as text. Blocks which are not closed or not opened are returned as TemplateError.
+func parseTemplate(src string) ([]node, error) {
+	stack := []*openBlock{{}}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			stack[len(stack)-1].add(textNode(text.String()))
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(src); {
+		start := strings.IndexByte(src[i:], '%')
+		if start < 0 {
+			text.WriteString(src[i:])
+			break
+		}
+		start += i
+		text.WriteString(src[i:start])
+		if start+1 < len(src) && src[start+1] == '%' {
+			text.WriteByte('%')
+			i = start + 2
+			continue
+		}
+		end := strings.IndexByte(src[start+1:], '%')
+		if end < 0 {
+			text.WriteString(src[start:])
+			break
+		}
+		end += start + 1
+		content := src[start+1 : end]
+		if keyword, arg, ok := parseBlockTag(content); ok {
+			flush()
+			top := stack[len(stack)-1]
+			switch keyword {
+			case "if", "each":
+				block := &openBlock{keyword: keyword, pos: start}
+				if keyword == "if" && strings.HasPrefix(arg, "not ") {
+					block.not = true
+					arg = strings.TrimSpace(arg[4:])
+				}
+				expr, ok := parseExpr(arg)
+				if !ok {
+					return nil, newTemplateError(src, start, "invalid expression in %"+content+"%")
+				}
+				block.expr = expr
+				stack = append(stack, block)
+			case "else":
+				if len(stack) == 1 {
+					return nil, newTemplateError(src, start, "%else% without %if% or %each%")
+				}
+				if top.inElse {
+					return nil, newTemplateError(src, start, "%"+top.keyword+"% already has an %else%")
+				}
+				top.inElse = true
+			case "end":
+				if len(stack) == 1 {
+					return nil, newTemplateError(src, start, "%end% without %if% or %each%")
+				}
+				stack = stack[:len(stack)-1]
+				stack[len(stack)-1].add(top.node())
+			}
+			i = end + 1
+			continue
+		}
+		expr, ok := parseExpr(content)
+		if !ok {
+			text.WriteByte('%')
+			i = start + 1
+			continue
+		}
+		expr.raw = src[start : end+1]
+		flush()
+		stack[len(stack)-1].add(expr)
+		i = end + 1
+	}
+	flush()
+	if len(stack) > 1 {
+		top := stack[len(stack)-1]
+		return nil, newTemplateError(src, top.pos, "%"+top.keyword+"% is not closed by %end%")
+	}
+	return stack[0].body, nil
+}
+
+// parseExpr parses the content of a placeholder like price|currency(IDR)|upper
+func parseExpr(src string) (*exprNode, bool) {
+	if src != strings.TrimSpace(src) {
+		return nil, false
+	}
+	expr := &exprNode{}
+	for i, part := range splitOutside(src, '|') {
+		c, ok := parseCall(strings.TrimSpace(part))
+		if !ok {
+			return nil, false
+		}
+		if i == 0 {
+			expr.value = c
+		} else {
+			expr.filters = append(expr.filters, c)
+		}
+	}
+	return expr, true
+}
+
+// parseCall parses name or name(arg1, "arg 2")
+func parseCall(src string) (call, bool) {
+	c := call{name: src}
+	if open := strings.IndexByte(src, '('); open >= 0 {
+		if !strings.HasSuffix(src, ")") {
+			return c, false
+		}
+		c.name = src[:open]
+		c.hasArgs = true
+		args := src[open+1 : len(src)-1]
+		if strings.TrimSpace(args) != "" {
+			for _, arg := range splitOutside(args, ',') {
+				c.args = append(c.args, unquote(strings.TrimSpace(arg)))
+			}
+		}
+	}
+	return c, isName(c.name)
+}
+
+// isName checks whether name is a data path or a function name, example: Customer.Addresses[0].City
+func isName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, ch := range name {
+		switch {
+		case ch == '_' || ch == '@' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+		case i > 0 && (ch >= '0' && ch <= '9' || ch == '.' || ch == '[' || ch == ']' || ch == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitOutside splits src by sep, except inside quotes and parentheses
+func splitOutside(src string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth, last := 0, 0
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == sep && depth == 0:
+			parts = append(parts, src[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, src[last:])
+}
+
+// unquote removes the quotes around an argument like "a, b" or 'a, b'
+func unquote(arg string) string {
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
+
+// resolve gets the value of a placeholder from a function or from data
+func (sf *StringFormatter) resolve(c call, ctx *scope) (interface{}, bool) {
+	if !c.hasArgs {
+		if val, ok := ctx.lookup(c.name); ok {
+			return val, true
+		}
+	}
+	if fn, ok := sf.Functions[c.name]; ok {
+		return fn(ctx.data, c.args)
+	}
+	if fn, ok := builtinFunctions[c.name]; ok {
+		return fn(sf, ctx, c.args)
+	}
+	return nil, false
+}
+
+func (sf *StringFormatter) filter(name string) (TemplateFilter, bool) {
+	if f, ok := sf.Filters[name]; ok {
+		return f, true
+	}
+	f, ok := builtinFilters[name]
+	if ok {
+		return func(value interface{}, args []string) interface{} {
+			return f(sf, value, args)
+		}, true
+	}
+	return nil, false
+}
+
+// lookup gets the member of data at a dotted path like Customer.Name using reflection.Get
+func lookup(data interface{}, path string) (interface{}, bool) {
+	if data == nil {
+		return nil, false
+	}
+	val, err := reflection.Get(data, path)
+	return val, err == nil
+}
+
+// toString prints a value resolved by a placeholder
+func toString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(val)
+}